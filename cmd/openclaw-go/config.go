@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/gateway"
+	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/logging"
+	"github.com/openclaw/openclaw-go/internal/routing"
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = `usage: openclaw-go config <command> [flags]
+
+commands:
+  check     validate the config file (syntax, unknown keys, agents, bindings, plugin ids); exits 1 on errors
+  print     print the config merged from its includes and overlay, as written (secret literals redacted)
+            --resolved: print the effective config after ${VAR} expansion and defaults, secrets redacted
+`
+
+// configCommand inspects the config file without starting the gateway.
+func configCommand(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+	sub := args[0]
+	fs := flag.NewFlagSet("config "+sub, flag.ContinueOnError)
+	configPath := fs.String("config", "", "Config file path (or OPENCLAW_CONFIG env)")
+	env := fs.String("env", "", "Environment overlay to merge, e.g. prod for openclaw.prod.yaml (or OPENCLAW_ENV env)")
+	resolved := fs.Bool("resolved", false, "print: show the effective config instead of the merged files")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, configUsage); fs.PrintDefaults() }
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	path := *configPath
+	if path == "" {
+		path = config.ResolveConfigPath()
+	}
+
+	loadOptionalSecrets()
+	*env = firstNonEmpty(*env, config.Getenv(config.EnvVar))
+	switch sub {
+	case "check":
+		if _, err := os.Stat(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		cfg, err := config.LoadEnv(path, *env)
+		if err == nil {
+			err = validateConfig(cfg)
+		}
+		if err != nil {
+			printConfigErrors(path, err)
+			return 1
+		}
+		fmt.Printf("%s: ok (%d agents, %d bindings)\n", strings.Join(cfg.Files(), " + "), len(cfg.Agents.List), len(cfg.Bindings))
+		if settings := gateway.RestartOnlySettings(cfg); len(settings) > 0 {
+			fmt.Printf("read at startup only, restart the gateway after changing: %s\n", strings.Join(settings, ", "))
+		}
+	case "print":
+		if _, err := os.Stat(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		var out []byte
+		var files []string
+		if *resolved {
+			cfg, err := config.LoadEnv(path, *env)
+			if err == nil {
+				out, err = yaml.Marshal(cfg)
+			}
+			if err != nil {
+				printConfigErrors(path, err)
+				return 1
+			}
+			files = cfg.Files()
+		} else {
+			var err error
+			if out, files, err = config.LoadRaw(path, *env); err != nil {
+				printConfigErrors(path, err)
+				return 1
+			}
+		}
+		// Values resolved from secret references never reach out; Redact also catches a loaded
+		// secret pasted into a plain field.
+		fmt.Printf("# %s\n%s", strings.Join(files, " + "), logging.Redact(string(out)))
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+	return 0
+}
+
+// loadOptionalSecrets loads the secrets file when there is one, so that ${VAR} and env: references
+// in the config resolve the same way they do for the gateway.
+func loadOptionalSecrets() {
+	path := config.ResolveSecretsPath()
+	if err := config.LoadSecrets(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "load secrets:", err)
+	}
+}
+
+// validateConfig runs the semantic checks against the registered plugins, plus identity links.
+func validateConfig(cfg *config.Config) error {
+	var opts config.ValidateOptions
+	for _, p := range llm.List() {
+		opts.LLMProviders = append(opts.LLMProviders, string(p.ID()))
+	}
+	for _, p := range llm.ListEmbeddings() {
+		opts.EmbeddingsProviders = append(opts.EmbeddingsProviders, string(p.ID()))
+	}
+	slices.Sort(opts.LLMProviders)
+	slices.Sort(opts.EmbeddingsProviders)
+	err := cfg.Validate(opts)
+	if lerr := routing.ValidateIdentityLinks(cfg.Session.IdentityLinks); lerr != nil {
+		var errs config.Errors
+		errors.As(err, &errs)
+		err = append(errs, config.Issue{Path: "session.identity_links", Msg: lerr.Error()})
+	}
+	return err
+}
+
+// printConfigErrors prints one line per issue as path:line:column: message.
+func printConfigErrors(path string, err error) {
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return
+	}
+	for _, i := range errs {
+		pos := path
+		if i.File != "" {
+			pos = i.File
+		}
+		if i.Line > 0 {
+			pos += fmt.Sprintf(":%d", i.Line)
+			if i.Column > 0 {
+				pos += fmt.Sprintf(":%d", i.Column)
+			}
+		}
+		msg := i.Msg
+		if i.Path != "" {
+			msg = i.Path + ": " + msg
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", pos, msg)
+	}
+}
+
+// logConfigErrors logs each issue of a config that failed to load or validate.
+func logConfigErrors(path string, err error) {
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		slog.Error("load config", "path", path, "err", err)
+		return
+	}
+	for _, i := range errs {
+		file := path
+		if i.File != "" {
+			file = i.File
+		}
+		slog.Error("invalid config", "path", file, "line", i.Line, "column", i.Column, "field", i.Path, "err", i.Msg)
+	}
+}
@@ -5,77 +5,166 @@ import (
 	"flag"
 	"log/slog"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 
 	"github.com/openclaw/openclaw-go/internal/channels"
 	"github.com/openclaw/openclaw-go/internal/channels/discord"
+	"github.com/openclaw/openclaw-go/internal/commands"
 	"github.com/openclaw/openclaw-go/internal/config"
 	"github.com/openclaw/openclaw-go/internal/dispatch"
 	"github.com/openclaw/openclaw-go/internal/gateway"
 	"github.com/openclaw/openclaw-go/internal/inbound"
 	"github.com/openclaw/openclaw-go/internal/llm"
 	"github.com/openclaw/openclaw-go/internal/llm/kimi"
+	"github.com/openclaw/openclaw-go/internal/llm/openai"
+	"github.com/openclaw/openclaw-go/internal/logging"
+	"github.com/openclaw/openclaw-go/internal/memory"
+	"github.com/openclaw/openclaw-go/internal/routing"
+	"github.com/openclaw/openclaw-go/internal/workspace"
 )
 
 func main() {
+	// 日志统一经过脱敏：认证文件与配置中解析出的 token / API Key 不会出现在日志里
+	slog.SetDefault(slog.New(logging.NewRedactingHandler(slog.NewTextHandler(os.Stderr, nil))))
+
+	// 注册 LLM 与向量插件（与 channel 插件解耦，后续换大模型只需换插件；子命令校验配置时也会用到）
+	llm.Register(&kimi.Plugin{})
+	llm.RegisterEmbeddings(&openai.Embeddings{})
+	llm.RegisterEmbeddings(kimi.NewEmbeddings())
+	llm.RegisterEmbeddings(llm.HashEmbeddings{})
+
+	// 子命令（不启动网关，无需 token / secrets）
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			os.Exit(configCommand(os.Args[2:]))
+		case "memory":
+			os.Exit(memoryCommand(os.Args[2:]))
+		case "route":
+			os.Exit(routeCommand(os.Args[2:]))
+		case "sessions":
+			os.Exit(sessionsCommand(os.Args[2:]))
+		}
+	}
+
 	tokenFlag := flag.String("token", "", "Discord bot token (or DISCORD_TOKEN env)")
 	configPath := flag.String("config", "", "Config file path (or OPENCLAW_CONFIG env)")
+	envFlag := flag.String("env", "", "Config overlay to merge, e.g. prod for openclaw.prod.yaml (or OPENCLAW_ENV env)")
 	flag.Parse()
+	logging.AddSecrets(*tokenFlag)
 
 	// 本地认证文件：必须存在，否则提示并退出（该文件不提交、不 push）
 	secretsPath := config.ResolveSecretsPath()
 	if err := config.LoadSecrets(secretsPath); err != nil {
 		if os.IsNotExist(err) {
 			slog.Error("secrets file not found",
-				"path", secretsPath,
-				"hint", "create goopenclaw.secrets from goopenclaw.secrets.example and fill in DISCORD_TOKEN, MOONSHOT_API_KEY etc.")
+				"searched", strings.Join(config.SecretsSearchPaths(), ", "),
+				"hint", "create goopenclaw.secrets from goopenclaw.secrets.example (chmod 600) and fill in DISCORD_TOKEN, MOONSHOT_API_KEY etc.")
 			os.Exit(1)
 		}
 		slog.Error("load secrets", "path", secretsPath, "err", err)
 		os.Exit(1)
 	}
 
-	token := *tokenFlag
-	if token == "" {
-		token = os.Getenv("DISCORD_TOKEN")
-	}
-	if token == "" {
-		slog.Error("discord token required (--token or DISCORD_TOKEN, or set in goopenclaw.secrets)")
-		os.Exit(1)
-	}
-
 	cfgPath := *configPath
 	if cfgPath == "" {
 		cfgPath = config.ResolveConfigPath()
 	}
-	cfg, err := config.Load(cfgPath)
+	// 环境覆盖层：--env 优先，其次 OPENCLAW_ENV（openclaw.yaml 之上再合并 openclaw.<env>.yaml）
+	env := firstNonEmpty(*envFlag, config.Getenv(config.EnvVar))
+	cfg, err := config.LoadEnv(cfgPath, env)
+	if err == nil {
+		err = validateConfig(cfg)
+	}
 	if err != nil {
-		slog.Error("load config", "path", cfgPath, "err", err)
+		logConfigErrors(cfgPath, err)
 		os.Exit(1)
 	}
+	if files := cfg.Files(); len(files) > 1 {
+		slog.Info("config loaded", "files", strings.Join(files, ", "))
+	}
+	accounts := discordAccounts(cfg, *tokenFlag)
+	if len(accounts) == 0 {
+		slog.Error("discord token required (--token, DISCORD_TOKEN, channels.discord.token or channels.discord.accounts)")
+		os.Exit(1)
+	}
+	configureProviders(cfg)
 
-	// 注册 LLM 插件（与 channel 插件解耦，后续换大模型只需换插件）
-	llm.Register(&kimi.Plugin{})
+	if cfg.Agents.Defaults.LLMProvider == "" {
+		slog.Warn("no llm provider configured, agent will echo only")
+	}
 
-	var llmPlugin llm.Plugin
-	if pid := cfg.Agents.Defaults.LLMProvider; pid != "" {
-		llmPlugin = llm.Get(llm.ProviderID(pid))
-		if llmPlugin == nil {
-			slog.Warn("llm provider not found, agent will echo only", "provider", pid)
-		}
+	sessions, err := openSessions(cfg)
+	if err != nil {
+		slog.Error("open session store", "dir", cfg.Session.StoreDir, "err", err)
+		os.Exit(1)
 	}
-	defaultModel := ""
-	if cfg != nil {
-		defaultModel = cfg.Agents.Defaults.DefaultModel
+	var memories *memory.Store
+	if m := cfg.Memory; m.Enabled == nil || *m.Enabled {
+		if memories, err = openMemory(cfg); err != nil {
+			slog.Error("open memory store", "dir", cfg.Memory.Dir, "err", err)
+			os.Exit(1)
+		}
 	}
+	workspaces := workspace.NewStore(cfg.Agents.Defaults.WorkspaceRoot, cfg.Agents.Defaults.WorkspaceMaxChars)
+	router := commands.NewRouter()
+	commands.RegisterBuiltins(router)
 
 	// Gateway as main process: create runtime, register plugins, start channels.
 	rt := &gateway.Runtime{
-		Config: cfg,
-		LLM:    llmPlugin,
-		DispatchInbound: func(ctx context.Context, msgCtx *inbound.MsgContext, d gateway.Dispatcher) error {
-			return dispatch.DispatchInbound(ctx, msgCtx, d, llmPlugin, defaultModel)
+		Sessions: sessions,
+		Commands: router,
+	}
+	rt.SetConfig(cfg)
+	rt.DispatchInbound = func(ctx context.Context, msgCtx *inbound.MsgContext, d gateway.Dispatcher) error {
+		// 每条消息读取当前配置：热加载后 llm_provider / default_model 等从下一条消息起生效
+		cfg := rt.Config()
+		var llmPlugin llm.Plugin
+		if pid := cfg.Agents.Defaults.LLMProvider; pid != "" {
+			llmPlugin = llm.Get(llm.ProviderID(pid))
+		}
+		return dispatch.DispatchInbound(ctx, msgCtx, d, dispatch.InboundOpts{
+			Cfg:          cfg,
+			LLM:          llmPlugin,
+			DefaultModel: cfg.Agents.Defaults.DefaultModel,
+			Sessions:     sessions,
+			Workspaces:   workspaces,
+			Memory:       memories,
+			Commands:     router,
+		})
+	}
+
+	// 配置热加载：轮询配置与认证文件的变化或收到 SIGHUP 时重新加载；校验失败则保留当前配置。
+	// 认证文件同时重新读取，轮换的 API Key 经 configureProviders 从下一条消息起生效。
+	reloader := &gateway.Reloader{
+		Path:    cfgPath,
+		Runtime: rt,
+		Load: func(path string) (*config.Config, error) {
+			if err := config.LoadSecrets(secretsPath); err != nil {
+				slog.Warn("config: reload secrets, keeping current values", "path", secretsPath, "err", err)
+			}
+			cfg, err := config.LoadEnv(path, env)
+			if err == nil {
+				err = validateConfig(cfg)
+			}
+			return cfg, err
 		},
+		Apply: configureProviders,
+		Watch: []string{secretsPath},
 	}
+	go reloader.Run(context.Background())
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reloader.Reload("SIGHUP")
+		}
+	}()
 	channels.Register(discord.Plugin{})
 
 	plugin := channels.Get(discord.Plugin{}.ID())
@@ -84,15 +173,73 @@ func main() {
 		os.Exit(1)
 	}
 
-	ctx := channels.StartAccountContext{
-		Cfg:       cfg,
-		AccountID: "default",
-		Account:   &discord.DiscordAccount{Token: token},
-		Runtime:   rt,
+	// 每个账号一个 bot 连接，全部退出（Ctrl+C）后进程结束
+	var wg sync.WaitGroup
+	var failed atomic.Bool
+	for _, acc := range accounts {
+		wg.Add(1)
+		go func(acc discordAccount) {
+			defer wg.Done()
+			ctx := channels.StartAccountContext{
+				Cfg:       cfg,
+				AccountID: acc.ID,
+				Account:   &discord.DiscordAccount{Token: acc.Token},
+				Runtime:   rt,
+			}
+			if err := plugin.StartAccount(ctx); err != nil {
+				slog.Error("discord exited", "account", acc.ID, "err", err)
+				failed.Store(true)
+			}
+		}(acc)
 	}
-
-	if err := plugin.StartAccount(ctx); err != nil {
-		slog.Error("discord exited", "err", err)
+	wg.Wait()
+	if failed.Load() {
 		os.Exit(1)
 	}
 }
+
+type discordAccount struct {
+	ID    string
+	Token string
+}
+
+// discordAccounts returns the bots to start: the enabled channels.discord.accounts, or a single
+// "default" account whose token comes from the flag, DISCORD_TOKEN or channels.discord.token.
+func discordAccounts(cfg *config.Config, tokenFlag string) []discordAccount {
+	dc := cfg.Channels.Discord
+	if len(dc.Accounts) == 0 {
+		token := tokenFlag
+		if token == "" {
+			token = config.Getenv("DISCORD_TOKEN")
+		}
+		if token == "" {
+			token = dc.Token.Value()
+		}
+		if token == "" {
+			return nil
+		}
+		return []discordAccount{{ID: routing.DefaultAccountID, Token: token}}
+	}
+	ids := make([]string, 0, len(dc.Accounts))
+	for id := range dc.Accounts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var out []discordAccount
+	for _, id := range ids {
+		a := dc.Accounts[id]
+		if a.Enabled != nil && !*a.Enabled {
+			continue
+		}
+		out = append(out, discordAccount{ID: routing.NormalizeAccountID(id), Token: a.Token.Value()})
+	}
+	return out
+}
+
+// configureProviders applies providers.<id> credentials and endpoints to the registered LLM plugins.
+// API keys not set there come from the plugin's environment variable or the secrets file. It runs at
+// startup and after every config reload.
+func configureProviders(cfg *config.Config) {
+	pc := cfg.Providers[string(kimi.ProviderID)]
+	llm.Register(&kimi.Plugin{APIKey: pc.APIKey.OrEnv(kimi.EnvAPIKey).Value(), BaseURL: pc.BaseURL})
+}
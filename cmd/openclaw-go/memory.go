@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/llm/openai"
+	"github.com/openclaw/openclaw-go/internal/memory"
+	"github.com/openclaw/openclaw-go/internal/routing"
+)
+
+const memoryUsage = `usage: openclaw-go memory <command> [flags] [args]
+
+commands:
+  users                       list users with memories for --agent
+  list   --user U             list a user's memories
+  search --user U <query>     search a user's memories
+  forget --user U <id>        delete one memory
+  purge  --user U             delete all of a user's memories (every agent unless --agent is set)
+
+U is an identity_links name or channel:id (e.g. discord:123456789012345678). purge also deletes
+memories saved under the user's other linked names and channel:id links.
+`
+
+// memoryCommand inspects and purges long-term memories, e.g. for privacy requests.
+func memoryCommand(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, memoryUsage)
+		return 2
+	}
+	sub := args[0]
+	fs := flag.NewFlagSet("memory "+sub, flag.ContinueOnError)
+	configPath := fs.String("config", "", "Config file path (or OPENCLAW_CONFIG env)")
+	agentFlag := fs.String("agent", "", "Agent id (default main; purge: all agents)")
+	userFlag := fs.String("user", "", "User: identity_links name or channel:id")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, memoryUsage); fs.PrintDefaults() }
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := loadConfig(*configPath, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load config:", err)
+		return 1
+	}
+	store, err := openMemory(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open memory store:", err)
+		return 1
+	}
+	// NormalizeAgentId maps "" to the default agent, so purge keeps "" to mean every agent.
+	agentID := ""
+	if *agentFlag != "" || sub != "purge" {
+		agentID = routing.NormalizeAgentId(*agentFlag)
+	}
+	user := ""
+	if *userFlag != "" {
+		user = canonicalUser(cfg, *userFlag)
+	} else if sub != "users" {
+		fmt.Fprintln(os.Stderr, "--user is required")
+		return 2
+	}
+
+	switch sub {
+	case "users":
+		users, err := store.Users(agentID)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, u := range users {
+			fmt.Println(u)
+		}
+	case "list":
+		entries, err := store.List(agentID, user)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, e := range entries {
+			fmt.Printf("%s  %s  %s\n", e.ID, e.CreatedAt.Local().Format("2006-01-02 15:04"), e.Text)
+		}
+	case "search":
+		hits, err := store.Search(context.Background(), agentID, user, strings.Join(fs.Args(), " "), 20)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, h := range hits {
+			fmt.Printf("%s  %.2f  %s\n", h.ID, h.Score, h.Text)
+		}
+	case "forget":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: openclaw-go memory forget --user U <id>")
+			return 2
+		}
+		ok, err := store.Forget(agentID, user, fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !ok {
+			fmt.Fprintln(os.Stderr, "no memory with id", fs.Arg(0))
+			return 1
+		}
+		fmt.Println("forgotten", fs.Arg(0))
+	case "purge":
+		aliases := routing.IdentityAliases(cfg.Session.IdentityLinks, *userFlag)
+		total := 0
+		for _, u := range aliases {
+			purged, err := store.Purge(agentID, u)
+			agents := make([]string, 0, len(purged))
+			for a := range purged {
+				agents = append(agents, a)
+			}
+			slices.Sort(agents)
+			for _, a := range agents {
+				fmt.Printf("purged %d memories of %s from agent %s\n", purged[a], u, a)
+				total += purged[a]
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		if total == 0 {
+			fmt.Printf("no memories of %s\n", strings.Join(aliases, ", "))
+		}
+	default:
+		fmt.Fprint(os.Stderr, memoryUsage)
+		return 2
+	}
+	return 0
+}
+
+// openMemory opens the memory store configured in memory.dir (default ~/.openclaw/memory).
+func openMemory(cfg *config.Config) (*memory.Store, error) {
+	dir := cfg.Memory.Dir
+	if dir == "" {
+		dir = memory.DefaultDir()
+	}
+	store, err := memory.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	store.MaxEntries = cfg.Memory.MaxEntries
+	if e := cfg.Memory.Embeddings; e.Provider != "" {
+		id := llm.ProviderID(e.Provider)
+		pc := cfg.Providers[e.Provider]
+		baseURL := firstNonEmpty(e.BaseURL, pc.BaseURL)
+		// API Key 一律取自配置：memory.embeddings.api_key > providers.<id>.api_key > 环境变量（api_key_env 或插件默认）
+		apiKey := func(defaultEnv string) string {
+			secret := e.APIKey
+			if !secret.IsSet() {
+				secret = pc.APIKey
+			}
+			return secret.OrEnv(firstNonEmpty(e.APIKeyEnv, defaultEnv, openai.EnvAPIKey)).Value()
+		}
+		p := llm.GetEmbeddings(id)
+		if base, ok := p.(*openai.Embeddings); ok {
+			c := *base
+			c.BaseURL = firstNonEmpty(baseURL, c.BaseURL)
+			c.APIKeyEnv = firstNonEmpty(e.APIKeyEnv, c.APIKeyEnv)
+			c.APIKey = apiKey(c.APIKeyEnv)
+			p = &c
+		} else if p == nil && baseURL != "" {
+			// 未注册的 id 配合 base_url：视为自建的 OpenAI 兼容服务
+			p = &openai.Embeddings{Provider: id, BaseURL: baseURL, APIKey: apiKey(""), APIKeyEnv: e.APIKeyEnv}
+		}
+		if p == nil {
+			return nil, fmt.Errorf("memory.embeddings: unknown provider %q", e.Provider)
+		}
+		store.Embedder = llm.Embedder{Plugin: p, Name: e.Model}
+		store.MinScore = e.MinScore
+	}
+	return store, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// canonicalUser maps "channel:id" to the identity memories are stored under; other values are
+// taken as identity_links names.
+func canonicalUser(cfg *config.Config, user string) string {
+	if channel, id, ok := strings.Cut(user, ":"); ok {
+		return routing.CanonicalIdentity(cfg.Session.IdentityLinks, channel, id)
+	}
+	return routing.NormalizeToken(user)
+}
+
+// loadConfig loads the config from path, OPENCLAW_CONFIG or the default location, with the env
+// overlay (or OPENCLAW_ENV when env is empty).
+func loadConfig(path, env string) (*config.Config, error) {
+	if path == "" {
+		path = config.ResolveConfigPath()
+	}
+	loadOptionalSecrets()
+	return config.LoadEnv(path, firstNonEmpty(env, config.Getenv(config.EnvVar)))
+}
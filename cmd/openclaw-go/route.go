@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/routing"
+)
+
+const routeUsage = `usage: openclaw-go route explain [flags]
+
+Resolves the agent for a message described by the flags and shows the session keys, the winning
+binding and why every other binding was rejected.
+
+example:
+  openclaw-go route explain --channel discord --peer channel:123 --guild 456
+`
+
+// routeCommand debugs routing without starting the gateway.
+func routeCommand(args []string) int {
+	if len(args) == 0 || args[0] != "explain" {
+		fmt.Fprint(os.Stderr, routeUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("route explain", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Config file path (or OPENCLAW_CONFIG env)")
+	env := fs.String("env", "", "Environment overlay to merge (or OPENCLAW_ENV env)")
+	channel := fs.String("channel", "discord", "Channel the message arrives on")
+	account := fs.String("account", routing.DefaultAccountID, "Account id of the bot that received it")
+	peer := fs.String("peer", "", "Conversation as kind:id, kind one of dm, group, channel, thread (e.g. channel:123)")
+	parent := fs.String("parent", "", "Parent channel of a thread as kind:id (e.g. channel:123)")
+	guild := fs.String("guild", "", "Guild (server) id")
+	team := fs.String("team", "", "Team id")
+	roles := fs.String("roles", "", "Sender's role ids, comma-separated")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, routeUsage); fs.PrintDefaults() }
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	input := routing.ResolveAgentRouteInput{Channel: *channel, AccountID: *account, GuildID: *guild, TeamID: *team}
+	for _, r := range strings.Split(*roles, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			input.MemberRoleIDs = append(input.MemberRoleIDs, r)
+		}
+	}
+	var err error
+	if input.Peer, err = parseRoutePeer(*peer); err == nil {
+		input.ParentPeer, err = parseRoutePeer(*parent)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	path := *configPath
+	if path == "" {
+		path = config.ResolveConfigPath()
+	}
+	if input.Cfg, err = loadConfig(path, *env); err != nil {
+		printConfigErrors(path, err)
+		return 1
+	}
+	fmt.Print(routing.ExplainAgentRoute(input))
+	return 0
+}
+
+// parseRoutePeer parses "kind:id"; "" means no peer.
+func parseRoutePeer(s string) (*routing.RoutePeer, error) {
+	if s == "" {
+		return nil, nil
+	}
+	kind, id, ok := strings.Cut(s, ":")
+	switch routing.RoutePeerKind(kind) {
+	case routing.PeerDM, routing.PeerGroup, routing.PeerChannel, routing.PeerThread:
+	default:
+		ok = false
+	}
+	if !ok || id == "" {
+		return nil, fmt.Errorf("invalid peer %q (want kind:id with kind dm, group, channel or thread)", s)
+	}
+	return &routing.RoutePeer{Kind: routing.RoutePeerKind(kind), ID: id}, nil
+}
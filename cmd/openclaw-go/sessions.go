@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/session"
+)
+
+const sessionsUsage = `usage: openclaw-go sessions <command> [flags] [args]
+
+commands:
+  list                        list sessions with their current conversation
+  archived <session key>      list the conversations of a session that were reset
+  show <session id>           print the transcript of a live or archived conversation
+`
+
+// sessionsCommand reads the session store, e.g. to look up a conversation after it was reset.
+func sessionsCommand(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(os.Stderr, sessionsUsage)
+		return 2
+	}
+	sub := args[0]
+	fs := flag.NewFlagSet("sessions "+sub, flag.ContinueOnError)
+	configPath := fs.String("config", "", "Config file path (or OPENCLAW_CONFIG env)")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, sessionsUsage); fs.PrintDefaults() }
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if (sub == "list" && fs.NArg() != 0) || (sub != "list" && fs.NArg() != 1) {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := loadConfig(*configPath, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load config:", err)
+		return 1
+	}
+	store, err := openSessions(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch sub {
+	case "list":
+		keys := store.Keys()
+		slices.Sort(keys)
+		for _, k := range keys {
+			e := store.Get(k)
+			fmt.Printf("%s  %s  %3d turns  %s\n", e.SessionKey, e.SessionID, e.Usage.Turns, e.UpdatedAt.Local().Format("2006-01-02 15:04"))
+		}
+	case "archived":
+		archived, err := store.Archived(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(archived) == 0 {
+			fmt.Println("no archived conversations for", fs.Arg(0))
+		}
+		for _, a := range archived {
+			fmt.Printf("%s  %s - %s  %3d turns  %s\n", a.SessionID,
+				a.CreatedAt.Local().Format("2006-01-02 15:04"), a.ArchivedAt.Local().Format("2006-01-02 15:04"), a.Usage.Turns, a.Reason)
+		}
+	case "show":
+		lines, err := store.Transcript(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, l := range lines {
+			fmt.Printf("[%s] %s: %s\n", l.Time.Local().Format("2006-01-02 15:04"), l.Role, l.Content)
+		}
+	default:
+		fmt.Fprint(os.Stderr, sessionsUsage)
+		return 2
+	}
+	return 0
+}
+
+// openSessions opens the session store configured in session.store_dir (default ~/.openclaw/sessions).
+func openSessions(cfg *config.Config) (*session.Store, error) {
+	dir := cfg.Session.StoreDir
+	if dir == "" {
+		dir = session.DefaultDir()
+	}
+	return session.Open(dir)
+}
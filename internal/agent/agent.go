@@ -3,38 +3,119 @@ package agent
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 
+	"github.com/openclaw/openclaw-go/internal/config"
 	"github.com/openclaw/openclaw-go/internal/inbound"
 	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/media"
+	"github.com/openclaw/openclaw-go/internal/memory"
+	"github.com/openclaw/openclaw-go/internal/routing"
+	"github.com/openclaw/openclaw-go/internal/session"
+	"github.com/openclaw/openclaw-go/internal/workspace"
 )
 
-// Run processes a message and returns the reply text (in-process, no HTTP).
-// 若 llmPlugin 非 nil 则调用其 Chat；否则回显占位（便于未配置 LLM 时仍可运行）。
-// defaultModel 可选，非空时作为 ChatRequest.Model 传给插件（如 kimi-k2-turbo-preview）。
-func Run(ctx context.Context, msgCtx *inbound.MsgContext, llmPlugin llm.Plugin, defaultModel string) (string, error) {
+const defaultSystemPrompt = "你是 Kimi，由 Moonshot AI 提供的人工智能助手，你更擅长中文和英文的对话。你会为用户提供安全、有帮助、准确的回答。"
+
+// RunParams 为 agent.Run 的依赖。
+type RunParams struct {
+	Cfg *config.Config
+	// LLM 为 nil 时回显占位（便于未配置 LLM 时仍可运行）。
+	LLM llm.Plugin
+	// DefaultModel 可选，非空时作为 ChatRequest.Model 传给插件（如 kimi-k2-turbo-preview）；会话的 /model 覆盖优先。
+	DefaultModel string
+	// Sessions 保存会话历史与用量；为 nil 时每条消息独立处理。
+	Sessions *session.Store
+	// Workspaces 提供 agent 工作区文件（人设、指令、笔记），追加到系统提示词；可为 nil。
+	Workspaces *workspace.Store
+	// Memory 非 nil 时向模型提供长期记忆工具（memory_save / memory_search / memory_forget）。
+	Memory *memory.Store
+}
+
+// Reply is the agent's output: text plus files (generated by the model or split off from the text).
+type Reply struct {
+	Text  string
+	Files []media.File
+}
+
+// Run processes a message and returns the reply (in-process, no HTTP).
+func Run(ctx context.Context, msgCtx *inbound.MsgContext, p RunParams) (Reply, error) {
 	msgCtx.Finalize()
-	if strings.TrimSpace(msgCtx.BodyForCommands) == "" {
-		return "", nil
+	if strings.TrimSpace(msgCtx.BodyForCommands) == "" && len(msgCtx.Media) == 0 {
+		return Reply{}, nil
+	}
+	var entry session.Entry
+	useSession := p.Sessions != nil && msgCtx.SessionKey != ""
+	if useSession {
+		// 按 session.reset 策略判断是否过期（空闲/每日），过期则归档旧会话并开启新会话。
+		policy, err := session.PolicyFor(p.Cfg, msgCtx.ChatType)
+		if err != nil {
+			slog.Warn("agent: session reset policy", "err", err)
+		}
+		entry, _ = p.Sessions.Resolve(msgCtx.SessionKey, policy)
+		if entry.AgentOverride != "" {
+			msgCtx.AgentID = entry.AgentOverride
+		}
 	}
-	if llmPlugin != nil {
-		req := &llm.ChatRequest{
-			Model: defaultModel,
-			Messages: []llm.Message{
-				{Role: "system", Content: "你是 Kimi，由 Moonshot AI 提供的人工智能助手，你更擅长中文和英文的对话。你会为用户提供安全、有帮助、准确的回答。"},
-				{Role: "user", Content: msgCtx.BodyForCommands},
-			},
-		}
-		resp, err := llmPlugin.Chat(ctx, req)
+	if p.LLM != nil {
+		model := p.DefaultModel
+		if entry.ModelOverride != "" {
+			model = entry.ModelOverride
+		}
+		// 系统提示词与信封可按 agent 配置模板（变量见 tmpl.Vars）。
+		vars := promptVars(p.Cfg, msgCtx)
+		system, header := systemPrompt(p.Cfg, vars), ""
+		if ws := p.Workspaces.Prompt(p.Cfg, msgCtx.AgentID); ws != "" {
+			system += "\n\n" + ws
+		}
+		if env := envelopeConfig(p.Cfg, msgCtx.AgentID); envelopeEnabled(env) {
+			// 信封：标明发送者、会话与时间，便于多人共享的频道会话区分发言者。
+			system += "\n" + envelopeSystemNote
+			header = envelope(env, msgCtx, vars)
+		}
+		// 附件：文本内联；图片/PDF 在模型支持时作为内容段发送，否则降级为文字描述。
+		userMsg, storedMsg := userMessages(msgCtx, header, inputSupport(p.Cfg, p.LLM, model))
+		messages := []llm.Message{{Role: "system", Content: system}}
+		messages = append(messages, entry.History...)
+		messages = append(messages, userMsg)
+		var tools []tool
+		env := toolEnv{AgentID: msgCtx.AgentID, SessionKey: msgCtx.SessionKey, Memory: p.Memory, Msg: msgCtx, Params: &p}
+		if p.Memory != nil && msgCtx.SenderId != "" {
+			// 长期记忆按 agent 与发送者的规范身份（identity_links）隔离。
+			var links map[string][]string
+			if p.Cfg != nil {
+				links = p.Cfg.Session.IdentityLinks
+			}
+			env.User = routing.CanonicalIdentity(links, msgCtx.Provider, msgCtx.SenderId)
+			tools = append(tools, memoryTools...)
+		}
+		// agents.list[].delegates：可咨询其他 agent（子会话 {会话 key}:sub:{agent}），或把后续对话转交给它。
+		tools = append(tools, delegationTools(ctx, p.Cfg, msgCtx.AgentID, useSession)...)
+		resp, err := chat(ctx, p.LLM, model, messages, tools, env)
 		if err != nil {
-			return "", fmt.Errorf("agent llm chat: %w", err)
+			return Reply{}, fmt.Errorf("agent llm chat: %w", err)
+		}
+		text := strings.TrimSpace(resp.Content)
+		if useSession {
+			// 历史保存完整文本（含代码块），生成的文件以描述代替。
+			p.Sessions.Append(msgCtx.SessionKey, resp.Usage, storedMsg, llm.Message{Role: "assistant", Content: withFileNotes(text, resp.Files)})
 		}
-		return strings.TrimSpace(resp.Content), nil
+		reply := Reply{Text: text, Files: resp.Files}
+		if p.Cfg != nil && p.Cfg.Media.SnippetFileLines > 0 {
+			var snippets []media.File
+			reply.Text, snippets = extractSnippets(reply.Text, p.Cfg.Media.SnippetFileLines)
+			reply.Files = append(snippets, reply.Files...)
+		}
+		return reply, nil
 	}
 	// 无 LLM 插件时回显占位
 	body := msgCtx.BodyForCommands
 	if len(body) > 80 {
 		body = body[:80] + "..."
 	}
-	return fmt.Sprintf("Received: %s (session: %s)", body, msgCtx.SessionKey), nil
+	for i := range msgCtx.Media {
+		body += " " + msgCtx.Media[i].Describe()
+	}
+	return Reply{Text: fmt.Sprintf("Received: %s (session: %s)", body, msgCtx.SessionKey)}, nil
 }
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/inbound"
+)
+
+// Quoted context is cut to these lengths (in characters) so it can't crowd out the conversation.
+const (
+	maxReplyQuoteChars   = 1000
+	maxHistoryEntryChars = 500
+)
+
+// replyBlock renders the replied-to message between <replying_to> tags, or "" when there is none.
+func replyBlock(msgCtx *inbound.MsgContext) string {
+	q := msgCtx.ReplyTo
+	if q == nil || q.Body == "" {
+		return ""
+	}
+	return fmt.Sprintf("<replying_to sender=%q>\n%s\n</replying_to>", q.SenderName, truncateRunes(q.Body, maxReplyQuoteChars))
+}
+
+// historyBlock renders earlier channel messages between <recent_channel_messages> tags, one per line.
+func historyBlock(msgCtx *inbound.MsgContext) string {
+	if len(msgCtx.ChannelHistory) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<recent_channel_messages>\n")
+	for _, q := range msgCtx.ChannelHistory {
+		body := strings.ReplaceAll(truncateRunes(q.Body, maxHistoryEntryChars), "\n", " ")
+		if q.Timestamp > 0 {
+			fmt.Fprintf(&b, "[%s] ", time.UnixMilli(q.Timestamp).Format("2006-01-02 15:04"))
+		}
+		fmt.Fprintf(&b, "%s: %s\n", q.SenderName, body)
+	}
+	b.WriteString("</recent_channel_messages>")
+	return b.String()
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
+
+// joinSections joins the non-empty sections with blank lines.
+func joinSections(sections ...string) string {
+	var out []string
+	for _, s := range sections {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// joinLines puts header on its own line above body; either may be empty.
+func joinLines(header, body string) string {
+	if header == "" || body == "" {
+		return header + body
+	}
+	return header + "\n" + body
+}
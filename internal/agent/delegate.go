@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/routing"
+	"github.com/openclaw/openclaw-go/internal/session"
+)
+
+// Defaults for agents.defaults.delegation.
+const (
+	defaultDelegationDepth   = 2
+	defaultDelegationTimeout = 120 * time.Second
+)
+
+// chainKey is the context key of the agents a delegated run was called from, outermost first.
+type chainKey struct{}
+
+func delegationChain(ctx context.Context) []string {
+	chain, _ := ctx.Value(chainKey{}).([]string)
+	return chain
+}
+
+func delegationLimits(cfg *config.Config) (depth int, timeout time.Duration, handoff bool) {
+	depth, timeout, handoff = defaultDelegationDepth, defaultDelegationTimeout, true
+	if cfg == nil {
+		return
+	}
+	d := cfg.Agents.Defaults.Delegation
+	if d.MaxDepth > 0 {
+		depth = d.MaxDepth
+	}
+	if d.TimeoutSeconds > 0 {
+		timeout = time.Duration(d.TimeoutSeconds) * time.Second
+	}
+	if d.Handoff != nil {
+		handoff = *d.Handoff
+	}
+	return
+}
+
+// delegates returns the agents agentID may delegate to (agents.list[].delegates), in list order.
+func delegates(cfg *config.Config, agentID string) []config.AgentEntry {
+	self, ok := cfg.Agent(agentID)
+	if !ok || len(self.Delegates) == 0 {
+		return nil
+	}
+	var out []config.AgentEntry
+	for _, a := range cfg.Agents.List {
+		id := routing.NormalizeAgentId(a.ID)
+		if id == routing.NormalizeAgentId(agentID) {
+			continue
+		}
+		if slices.ContainsFunc(self.Delegates, func(d string) bool {
+			d = strings.TrimSpace(d)
+			return d == "*" || routing.NormalizeAgentId(d) == id
+		}) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// delegationTools returns agent_delegate, and agent_handoff for the conversation's own agent, when
+// agentID has delegates and the depth limit allows another level.
+func delegationTools(ctx context.Context, cfg *config.Config, agentID string, withSession bool) []tool {
+	targets := delegates(cfg, agentID)
+	depth, _, handoff := delegationLimits(cfg)
+	chain := delegationChain(ctx)
+	if len(targets) == 0 || len(chain) >= depth {
+		return nil
+	}
+	ids := make([]string, len(targets))
+	var list strings.Builder
+	for i, a := range targets {
+		ids[i] = routing.NormalizeAgentId(a.ID)
+		list.WriteString("\n- " + ids[i])
+		if a.Description != "" {
+			list.WriteString(": " + a.Description)
+		}
+	}
+	agentProp := map[string]any{"type": "string", "enum": ids, "description": "Agent id."}
+	tools := []tool{{
+		Tool: toolSpec("agent_delegate",
+			"Ask another agent and wait for its answer, e.g. to consult a specialist. It sees only your message, "+
+				"so include everything it needs; it remembers earlier questions from this conversation. Agents:"+list.String(),
+			map[string]any{
+				"agent_id": agentProp,
+				"message":  map[string]any{"type": "string", "description": "The question or task for the agent."},
+			},
+			"agent_id", "message"),
+		run: agentDelegate,
+	}}
+	// Only the agent the user talks to can pass the conversation on, and only with a session to record it in.
+	if handoff && withSession && len(chain) == 0 {
+		tools = append(tools, tool{
+			Tool: toolSpec("agent_handoff",
+				"Hand the rest of this conversation to another agent: it answers the user's next messages, with this conversation's history. "+
+					"Use it when the user's needs are clearly that agent's job, then tell the user who takes over. Agents:"+list.String(),
+				map[string]any{
+					"agent_id": agentProp,
+					"reason":   map[string]any{"type": "string", "description": "Why, for the logs."},
+				},
+				"agent_id"),
+			run: agentHandoff,
+		})
+	}
+	return tools
+}
+
+// delegateTarget resolves the agent_id argument to an allowed delegate.
+func delegateTarget(env toolEnv, agentID string) (string, error) {
+	want := routing.NormalizeAgentId(agentID)
+	for _, a := range delegates(env.Params.Cfg, env.AgentID) {
+		if routing.NormalizeAgentId(a.ID) == want {
+			return want, nil
+		}
+	}
+	return "", fmt.Errorf("agent %q is not one of this agent's delegates", agentID)
+}
+
+func agentDelegate(ctx context.Context, env toolEnv, raw json.RawMessage) (string, error) {
+	var args struct {
+		AgentID string `json:"agent_id"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+	target, err := delegateTarget(env, args.AgentID)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(args.Message) == "" {
+		return "", errors.New("message is required")
+	}
+	depth, timeout, _ := delegationLimits(env.Params.Cfg)
+	chain := append(slices.Clip(delegationChain(ctx)), env.AgentID)
+	if len(chain) > depth {
+		return "", fmt.Errorf("delegation depth limit (%d) reached", depth)
+	}
+	if slices.Contains(chain, target) {
+		return "", fmt.Errorf("agent %s is already working on this request (%s)", target, strings.Join(chain, " -> "))
+	}
+
+	// The delegate answers in its own session under this one, so it keeps the thread of earlier
+	// consultations without seeing the user conversation.
+	parent := env.Msg
+	sub := *parent
+	sub.Body, sub.RawBody, sub.CommandBody = args.Message, args.Message, args.Message
+	sub.BodyForAgent, sub.BodyForCommands = "", ""
+	sub.AgentID = target
+	sub.SenderName = "agent " + env.AgentID
+	sub.SenderUsername = ""
+	sub.Media, sub.ReplyTo, sub.ChannelHistory = nil, nil, nil
+	if parent.SessionKey != "" {
+		sub.SessionKey = routing.BuildSubAgentSessionKey(parent.SessionKey, target)
+	}
+
+	subCtx, cancel := context.WithTimeout(context.WithValue(ctx, chainKey{}, chain), timeout)
+	defer cancel()
+	start := time.Now()
+	reply, err := Run(subCtx, &sub, *env.Params)
+	if errors.Is(subCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return "", fmt.Errorf("agent %s did not answer within %s", target, timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("agent %s: %w", target, err)
+	}
+	slog.Info("agent: delegated", "from", env.AgentID, "to", target, "session", sub.SessionKey, "took", time.Since(start).Round(time.Millisecond))
+	text := strings.TrimSpace(reply.Text)
+	if n := len(reply.Files); n > 0 {
+		text += fmt.Sprintf("\n(%s also produced %d file(s), not passed on)", target, n)
+	}
+	if text == "" {
+		return target + " returned an empty answer", nil
+	}
+	return text, nil
+}
+
+func agentHandoff(_ context.Context, env toolEnv, raw json.RawMessage) (string, error) {
+	var args struct {
+		AgentID string `json:"agent_id"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+	target, err := delegateTarget(env, args.AgentID)
+	if err != nil {
+		return "", err
+	}
+	// Same override as /agent, but the history is kept so the new agent has the context.
+	env.Params.Sessions.Update(env.SessionKey, func(e *session.Entry) { e.AgentOverride = target })
+	slog.Info("agent: handoff", "from", env.AgentID, "to", target, "session", env.SessionKey, "reason", args.Reason)
+	return fmt.Sprintf("done: %s answers the next messages in this conversation (the user can switch back with /agent default)", target), nil
+}
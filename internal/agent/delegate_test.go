@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/session"
+)
+
+// fakeLLM answers every chat with its function.
+type fakeLLM func(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error)
+
+func (fakeLLM) ID() llm.ProviderID { return "fake" }
+
+func (f fakeLLM) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return f(ctx, req)
+}
+
+// echoLLM answers with the last user message.
+var echoLLM = fakeLLM(func(_ context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return &llm.ChatResponse{Content: "answer: " + req.Messages[len(req.Messages)-1].Content}, nil
+})
+
+// delegationConfig has main, which may consult math and stats, and math, which may consult main.
+func delegationConfig(d config.DelegationConfig) *config.Config {
+	cfg := &config.Config{}
+	cfg.Agents.Defaults.Delegation = d
+	cfg.Agents.Defaults.Envelope.Enabled = new(bool)
+	cfg.Agents.List = []config.AgentEntry{
+		{ID: "main", Delegates: []string{"math", "stats"}},
+		{ID: "math", Description: "Numbers.", Delegates: []string{"*"}},
+		{ID: "stats"},
+	}
+	return cfg
+}
+
+// delegateEnv is the tool environment of agentID answering in session agent:main:main.
+func delegateEnv(cfg *config.Config, plugin llm.Plugin, agentID string) toolEnv {
+	msg := &inbound.MsgContext{Body: "question", SessionKey: "agent:main:main", AgentID: agentID, SenderId: "1"}
+	return toolEnv{
+		AgentID:    agentID,
+		SessionKey: msg.SessionKey,
+		Msg:        msg,
+		Params:     &RunParams{Cfg: cfg, LLM: plugin, Sessions: session.NewStore()},
+	}
+}
+
+func toolNames(tools []tool) []string {
+	var out []string
+	for _, t := range tools {
+		out = append(out, t.Name)
+	}
+	return out
+}
+
+func TestDelegationTools(t *testing.T) {
+	no := false
+	tests := []struct {
+		name        string
+		delegation  config.DelegationConfig
+		agent       string
+		chain       []string
+		withSession bool
+		want        string
+	}{
+		{name: "no delegates", agent: "stats", withSession: true},
+		{name: "top level", agent: "main", withSession: true, want: "agent_delegate agent_handoff"},
+		{name: "no session", agent: "main", want: "agent_delegate"},
+		{name: "handoff disabled", delegation: config.DelegationConfig{Handoff: &no}, agent: "main", withSession: true, want: "agent_delegate"},
+		{name: "nested", agent: "math", chain: []string{"main"}, withSession: true, want: "agent_delegate"},
+		{name: "depth reached", agent: "math", chain: []string{"main", "math"}, withSession: true},
+		{name: "max_depth", delegation: config.DelegationConfig{MaxDepth: 1}, agent: "math", chain: []string{"main"}, withSession: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.chain != nil {
+				ctx = context.WithValue(ctx, chainKey{}, tt.chain)
+			}
+			got := strings.Join(toolNames(delegationTools(ctx, delegationConfig(tt.delegation), tt.agent, tt.withSession)), " ")
+			if got != tt.want {
+				t.Errorf("tools = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentDelegate(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent string
+		chain []string
+		args  string
+		max   int
+		want  string
+		err   string
+	}{
+		{name: "answer", agent: "main", args: `{"agent_id":"Math","message":"2+2?"}`, want: "answer: 2+2?"},
+		{name: "not a delegate", agent: "stats", args: `{"agent_id":"math","message":"x"}`, err: `agent "math" is not one of this agent's delegates`},
+		{name: "unknown agent", agent: "main", args: `{"agent_id":"nobody","message":"x"}`, err: `agent "nobody" is not one of this agent's delegates`},
+		{name: "self", agent: "main", args: `{"agent_id":"main","message":"x"}`, err: `agent "main" is not one of this agent's delegates`},
+		{name: "no message", agent: "main", args: `{"agent_id":"math","message":" "}`, err: "message is required"},
+		{name: "depth limit", agent: "math", chain: []string{"stats"}, max: 1, args: `{"agent_id":"stats","message":"x"}`, err: "delegation depth limit (1) reached"},
+		{name: "cycle", agent: "math", chain: []string{"main"}, args: `{"agent_id":"main","message":"x"}`, err: "agent main is already working on this request (main -> math)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := delegateEnv(delegationConfig(config.DelegationConfig{MaxDepth: tt.max}), echoLLM, tt.agent)
+			ctx := context.Background()
+			if tt.chain != nil {
+				ctx = context.WithValue(ctx, chainKey{}, tt.chain)
+			}
+			got, err := agentDelegate(ctx, env, json.RawMessage(tt.args))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got %q, %v; want error %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentDelegateSession(t *testing.T) {
+	var seen []llm.Message
+	plugin := fakeLLM(func(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+		seen = req.Messages
+		if chain := delegationChain(ctx); len(chain) != 1 || chain[0] != "main" {
+			t.Errorf("chain = %v, want [main]", chain)
+		}
+		return &llm.ChatResponse{Content: "4"}, nil
+	})
+	env := delegateEnv(delegationConfig(config.DelegationConfig{}), plugin, "main")
+	env.Params.Sessions.Append(env.SessionKey, llm.Usage{}, llm.Message{Role: "user", Content: "secret user talk"})
+	for _, q := range []string{"2+2?", "and 2+3?"} {
+		if _, err := agentDelegate(context.Background(), env, json.RawMessage(`{"agent_id":"math","message":"`+q+`"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The delegate sees its own earlier exchange, not the user conversation.
+	var contents []string
+	for _, m := range seen[1:] {
+		contents = append(contents, m.Content)
+	}
+	if got := strings.Join(contents, "|"); got != "2+2?|4|and 2+3?" {
+		t.Errorf("delegate saw %q", got)
+	}
+	if e := env.Params.Sessions.Get("agent:main:main:sub:math"); e.Usage.Turns != 2 {
+		t.Errorf("sub session turns = %d, want 2", e.Usage.Turns)
+	}
+	if env.Msg.AgentID != "main" || env.Msg.Body != "question" {
+		t.Errorf("parent message changed: %+v", env.Msg)
+	}
+}
+
+func TestAgentDelegateTimeout(t *testing.T) {
+	slow := fakeLLM(func(ctx context.Context, _ *llm.ChatRequest) (*llm.ChatResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	env := delegateEnv(delegationConfig(config.DelegationConfig{TimeoutSeconds: 1}), slow, "main")
+	_, err := agentDelegate(context.Background(), env, json.RawMessage(`{"agent_id":"math","message":"x"}`))
+	if err == nil || err.Error() != "agent math did not answer within 1s" {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+
+	// A cancelled caller is reported as such, not as the delegate's timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = agentDelegate(ctx, env, json.RawMessage(`{"agent_id":"math","message":"x"}`))
+	if err == nil || strings.Contains(err.Error(), "did not answer") {
+		t.Errorf("err = %v, want the cancellation", err)
+	}
+}
+
+func TestAgentHandoff(t *testing.T) {
+	env := delegateEnv(delegationConfig(config.DelegationConfig{}), echoLLM, "main")
+	s := env.Params.Sessions
+	before := s.Append(env.SessionKey, llm.Usage{}, llm.Message{Role: "user", Content: "hi"})
+
+	if _, err := agentHandoff(context.Background(), env, json.RawMessage(`{"agent_id":"nobody"}`)); err == nil {
+		t.Error("handoff to a non-delegate succeeded")
+	}
+	if e := s.Get(env.SessionKey); e.AgentOverride != "" {
+		t.Errorf("override = %q after a refused handoff", e.AgentOverride)
+	}
+
+	got, err := agentHandoff(context.Background(), env, json.RawMessage(`{"agent_id":"Math","reason":"numbers"}`))
+	if err != nil || !strings.HasPrefix(got, "done: math answers") {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	e := s.Get(env.SessionKey)
+	if e.AgentOverride != "math" || e.SessionID != before.SessionID || len(e.History) != 1 {
+		t.Errorf("got override %q, session %s with %d messages; want math in the same session", e.AgentOverride, e.SessionID, len(e.History))
+	}
+
+	// The next message is answered by math, with its own delegation tools.
+	msg := &inbound.MsgContext{Body: "next", SessionKey: env.SessionKey, AgentID: "main", SenderId: "1"}
+	var tools []string
+	plugin := fakeLLM(func(_ context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+		for _, t := range req.Tools {
+			tools = append(tools, t.Name)
+		}
+		return &llm.ChatResponse{Content: "ok"}, nil
+	})
+	if _, err := Run(context.Background(), msg, RunParams{Cfg: env.Params.Cfg, LLM: plugin, Sessions: s}); err != nil {
+		t.Fatal(err)
+	}
+	if msg.AgentID != "math" || strings.Join(tools, " ") != "agent_delegate agent_handoff" {
+		t.Errorf("answered by %s with tools %v, want math with delegation tools", msg.AgentID, tools)
+	}
+}
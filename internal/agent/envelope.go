@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/tmpl"
+)
+
+// envelopeSystemNote tells the model what the envelope line is.
+const envelopeSystemNote = "每条用户消息开头的 [...] 行由平台附加，标明发送者、会话与时间，不是用户输入；回复时无需复述，多人对话时请据此区分发言者。"
+
+// envelopeConfig merges agents.defaults.envelope with the agent's own envelope settings.
+func envelopeConfig(cfg *config.Config, agentID string) config.EnvelopeConfig {
+	if cfg == nil {
+		return config.EnvelopeConfig{}
+	}
+	env := cfg.Agents.Defaults.Envelope
+	if a, ok := cfg.Agent(agentID); ok && a.Envelope != nil {
+		if a.Envelope.Enabled != nil {
+			env.Enabled = a.Envelope.Enabled
+		}
+		if a.Envelope.Timestamp != nil {
+			env.Timestamp = a.Envelope.Timestamp
+		}
+		if a.Envelope.Timezone != "" {
+			env.Timezone = a.Envelope.Timezone
+		}
+		if a.Envelope.Template != nil {
+			env.Template = a.Envelope.Template
+		}
+	}
+	return env
+}
+
+// envelopeEnabled reports whether inbound messages get an envelope line.
+func envelopeEnabled(env config.EnvelopeConfig) bool {
+	return env.Enabled == nil || *env.Enabled
+}
+
+// envelope returns the header line for an inbound message: the envelope template rendered with vars,
+// or by default e.g. "[discord · #general (channel) · from Alice (alice) · 2026-10-19 14:03 +08:00]".
+// env.Timezone, when set, overrides the agent's timezone in vars.
+func envelope(env config.EnvelopeConfig, msgCtx *inbound.MsgContext, vars tmpl.Vars) string {
+	if env.Timezone != "" {
+		vars.SetNow(vars.Now.In(loadLocation(env.Timezone)))
+	}
+	if env.Template != nil {
+		out, err := env.Template.Render(vars)
+		if err == nil {
+			return out
+		}
+		slog.Warn("agent: render envelope", "agent", vars.AgentID, "err", err)
+	}
+	var fields []string
+	if msgCtx.Provider != "" {
+		fields = append(fields, msgCtx.Provider)
+	}
+	switch {
+	case msgCtx.ChatType == "direct":
+		fields = append(fields, "direct message")
+	case msgCtx.ConversationLabel != "" && msgCtx.ChatType != "":
+		fields = append(fields, msgCtx.ConversationLabel+" ("+msgCtx.ChatType+")")
+	case msgCtx.ConversationLabel != "":
+		fields = append(fields, msgCtx.ConversationLabel)
+	}
+	sender := msgCtx.SenderName
+	if sender == "" {
+		sender = msgCtx.SenderUsername
+	}
+	if sender == "" {
+		sender = msgCtx.SenderId
+	}
+	if sender != "" {
+		fields = append(fields, "from "+sender)
+	}
+	if (env.Timestamp == nil || *env.Timestamp) && msgCtx.Timestamp > 0 {
+		fields = append(fields, vars.Now.Format("2006-01-02 15:04 -07:00"))
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(fields, " · ") + "]"
+}
@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/media"
+)
+
+// userMessages builds the user turn from the envelope header, the message text, its attachments and
+// the reply and channel context. full goes to the model; stored is the text-only form kept in session history, so image
+// and PDF data are not persisted. Text files are inlined; images and PDFs become content parts when the model accepts them and
+// a textual description otherwise.
+func userMessages(msgCtx *inbound.MsgContext, header string, accepts func(partType string) bool) (full, stored llm.Message) {
+	sections := []string{}
+	if body := strings.TrimSpace(msgCtx.BodyForCommands); body != "" {
+		sections = append(sections, body)
+	}
+	var parts []llm.ContentPart
+	for i := range msgCtx.Media {
+		f := &msgCtx.Media[i]
+		kind := f.Kind()
+		switch {
+		case f.Data == nil:
+			sections = append(sections, f.Describe())
+		case kind == media.KindText:
+			sections = append(sections, inlineText(f))
+		case kind == media.KindImage && accepts(llm.PartImageURL):
+			parts = append(parts, llm.ImagePart(f.DataURL()))
+			sections = append(sections, f.Describe())
+		case kind == media.KindDocument && accepts(llm.PartFile):
+			parts = append(parts, llm.FilePart(f.Name, f.DataURL()))
+			sections = append(sections, f.Describe())
+		default:
+			sections = append(sections, f.Describe()+" (the current model cannot read this attachment)")
+		}
+	}
+	// The replied-to message stays in session history; the channel history window does not,
+	// since the next turn fetches a fresh one.
+	body, reply := strings.Join(sections, "\n\n"), replyBlock(msgCtx)
+	text := joinLines(header, joinSections(reply, historyBlock(msgCtx), body))
+	stored = llm.Message{Role: "user", Content: joinLines(header, joinSections(reply, body))}
+	full = llm.Message{Role: "user", Content: text}
+	if len(parts) > 0 {
+		full.Parts = append([]llm.ContentPart{llm.TextPart(text)}, parts...)
+	}
+	return full, stored
+}
+
+// inlineText renders a text attachment as a fenced block under its description.
+func inlineText(f *media.File) string {
+	fence := "```"
+	for strings.Contains(f.Text(), fence) {
+		fence += "`"
+	}
+	s := fmt.Sprintf("%s\n%s\n%s\n%s", f.Describe(), fence, strings.TrimRight(f.Text(), "\n"), fence)
+	if f.Truncated {
+		s += "\n(truncated)"
+	}
+	return s
+}
+
+// inputSupport reports which content parts model accepts: what the plugin reports, plus images for
+// models listed in media.vision_models.
+func inputSupport(cfg *config.Config, p llm.Plugin, model string) func(partType string) bool {
+	return func(partType string) bool {
+		if llm.SupportsInput(p, model, partType) {
+			return true
+		}
+		return partType == llm.PartImageURL && cfg != nil && slices.Contains(cfg.Media.VisionModels, model)
+	}
+}
+
+// withFileNotes appends descriptions of generated files to a reply for session history.
+func withFileNotes(text string, files []media.File) string {
+	for i := range files {
+		text += "\n" + files[i].Describe()
+	}
+	return text
+}
+
+// snippetExts maps fence info strings to file extensions for extracted snippets.
+var snippetExts = map[string]string{
+	"go": ".go", "python": ".py", "py": ".py", "javascript": ".js", "js": ".js", "typescript": ".ts", "ts": ".ts",
+	"json": ".json", "yaml": ".yaml", "yml": ".yaml", "toml": ".toml", "sh": ".sh", "bash": ".sh", "shell": ".sh",
+	"sql": ".sql", "html": ".html", "css": ".css", "java": ".java", "rust": ".rs", "rs": ".rs", "c": ".c",
+	"cpp": ".cpp", "c++": ".cpp", "markdown": ".md", "md": ".md", "xml": ".xml", "diff": ".diff",
+}
+
+// extractSnippets moves fenced code blocks with at least minLines lines out of text into files named
+// snippet-N.<ext>, leaving a reference in their place. Unclosed fences are left as they are.
+func extractSnippets(text string, minLines int) (string, []media.File) {
+	lines := strings.Split(text, "\n")
+	var out []string
+	var files []media.File
+	for i := 0; i < len(lines); i++ {
+		open := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(open, "```") {
+			out = append(out, lines[i])
+			continue
+		}
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "```" {
+				end = j
+				break
+			}
+		}
+		if end < 0 || end-i-1 < minLines {
+			if end < 0 {
+				end = len(lines) - 1
+			}
+			out = append(out, lines[i:end+1]...)
+			i = end
+			continue
+		}
+		lang := ""
+		if f := strings.Fields(open[3:]); len(f) > 0 {
+			lang = strings.ToLower(f[0])
+		}
+		ext, ok := snippetExts[lang]
+		if !ok {
+			ext = ".txt"
+		}
+		body := strings.Join(lines[i+1:end], "\n") + "\n"
+		name := fmt.Sprintf("snippet-%d%s", len(files)+1, ext)
+		files = append(files, media.File{
+			Name:        name,
+			ContentType: "text/plain; charset=utf-8",
+			Size:        int64(len(body)),
+			Data:        []byte(body),
+		})
+		out = append(out, fmt.Sprintf("(code attached as `%s`)", name))
+		i = end
+	}
+	return strings.Join(out, "\n"), files
+}
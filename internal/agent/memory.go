@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/llm"
+)
+
+// memorySearchLimit is the default number of memories memory_search returns.
+const memorySearchLimit = 8
+
+// memoryTools are the long-term memory tools. Memories belong to the agent and the sender's
+// canonical identity, so a user's facts follow them across channels linked in session.identity_links.
+var memoryTools = []tool{
+	{
+		Tool: toolSpec("memory_save",
+			"Remember a lasting fact about the user you are talking to (preferences, names, ongoing projects) for future conversations. Save one short, self-contained statement per call.",
+			map[string]any{"text": map[string]any{"type": "string", "description": "The fact to remember."}},
+			"text"),
+		run: memorySave,
+	},
+	{
+		Tool: toolSpec("memory_search",
+			"Search what you remember about the user you are talking to. Returns matching memories with their ids; an empty query lists the most recent ones.",
+			map[string]any{
+				"query": map[string]any{"type": "string", "description": "What to look for (keywords or a question)."},
+				"limit": map[string]any{"type": "integer", "description": "Maximum results (default 8)."},
+			}),
+		run: memorySearch,
+	},
+	{
+		Tool: toolSpec("memory_forget",
+			"Delete a memory about the user by id (from memory_search), e.g. when it is outdated or the user asks you to forget it.",
+			map[string]any{"id": map[string]any{"type": "string", "description": "Memory id."}},
+			"id"),
+		run: memoryForget,
+	},
+}
+
+func memorySave(ctx context.Context, env toolEnv, raw json.RawMessage) (string, error) {
+	var args struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+	e, err := env.Memory.Save(ctx, env.AgentID, env.User, args.Text, env.SessionKey)
+	if err != nil {
+		return "", err
+	}
+	return "saved as " + e.ID, nil
+}
+
+func memorySearch(ctx context.Context, env toolEnv, raw json.RawMessage) (string, error) {
+	var args struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+	if args.Limit <= 0 {
+		args.Limit = memorySearchLimit
+	}
+	hits, err := env.Memory.Search(ctx, env.AgentID, env.User, args.Query, args.Limit)
+	if err != nil {
+		return "", err
+	}
+	if len(hits) == 0 {
+		return "no memories found", nil
+	}
+	var b strings.Builder
+	for _, h := range hits {
+		fmt.Fprintf(&b, "%s (%s): %s\n", h.ID, h.CreatedAt.Format("2006-01-02"), h.Text)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func memoryForget(_ context.Context, env toolEnv, raw json.RawMessage) (string, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+	ok, err := env.Memory.Forget(env.AgentID, env.User, strings.TrimSpace(args.ID))
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errors.New("no memory with that id")
+	}
+	return "forgotten", nil
+}
+
+// toolSpec builds an llm.Tool whose parameters are an object with the given properties.
+func toolSpec(name, description string, props map[string]any, required ...string) llm.Tool {
+	params := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		params["required"] = required
+	}
+	return llm.Tool{Name: name, Description: description, Parameters: params}
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/tmpl"
+)
+
+// promptVars collects the template variables for msgCtx, with times in the agent's timezone.
+func promptVars(cfg *config.Config, msgCtx *inbound.MsgContext) tmpl.Vars {
+	v := tmpl.Vars{
+		AgentID:      msgCtx.AgentID,
+		SessionKey:   msgCtx.SessionKey,
+		Channel:      msgCtx.Provider,
+		AccountID:    msgCtx.AccountID,
+		GuildID:      msgCtx.GuildID,
+		ChatType:     msgCtx.ChatType,
+		Conversation: msgCtx.ConversationLabel,
+		SenderName:   msgCtx.SenderName,
+		SenderID:     msgCtx.SenderId,
+		Vars:         map[string]string{},
+	}
+	tz := ""
+	if cfg != nil {
+		tz = cfg.Agents.Defaults.Timezone
+		for k, val := range cfg.Agents.Defaults.Vars {
+			v.Vars[k] = val
+		}
+		if a, ok := cfg.Agent(msgCtx.AgentID); ok {
+			if a.Timezone != "" {
+				tz = a.Timezone
+			}
+			for k, val := range a.Vars {
+				v.Vars[k] = val
+			}
+		}
+	}
+	now := time.Now()
+	if msgCtx.Timestamp > 0 {
+		now = time.UnixMilli(msgCtx.Timestamp)
+	}
+	v.SetNow(now.In(loadLocation(tz)))
+	return v
+}
+
+// systemPrompt renders the agent's system prompt template, or the default one from agents.defaults.
+// Without a template, or if rendering fails, the built-in prompt is used.
+func systemPrompt(cfg *config.Config, vars tmpl.Vars) string {
+	if cfg == nil {
+		return defaultSystemPrompt
+	}
+	t := cfg.Agents.Defaults.SystemPrompt
+	if a, ok := cfg.Agent(vars.AgentID); ok && a.SystemPrompt != nil {
+		t = a.SystemPrompt
+	}
+	if t == nil {
+		return defaultSystemPrompt
+	}
+	out, err := t.Render(vars)
+	if err != nil {
+		slog.Warn("agent: render system prompt", "agent", vars.AgentID, "err", err)
+		return defaultSystemPrompt
+	}
+	return out
+}
+
+// loadLocation returns the IANA timezone tz, or the host's local time when tz is empty or unknown.
+func loadLocation(tz string) *time.Location {
+	if tz == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		slog.Warn("agent: timezone", "timezone", tz, "err", err)
+		return time.Local
+	}
+	return loc
+}
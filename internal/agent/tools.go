@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/memory"
+)
+
+// maxToolRounds bounds how many times one message may go back to the model with tool results.
+const maxToolRounds = 5
+
+// toolEnv is what tool handlers know about the message being answered.
+type toolEnv struct {
+	AgentID    string
+	User       string // canonical identity of the sender (routing.CanonicalIdentity)
+	SessionKey string
+	Memory     *memory.Store
+	// Msg and Params are the message being answered and the run's dependencies, for delegation.
+	Msg    *inbound.MsgContext
+	Params *RunParams
+}
+
+// tool is a function the model may call.
+type tool struct {
+	llm.Tool
+	run func(ctx context.Context, env toolEnv, args json.RawMessage) (string, error)
+}
+
+// chat sends messages to the model. When it asks for tools, they are run and their results sent
+// back, until it answers in text; after maxToolRounds the tools are withdrawn to force an answer.
+// The returned usage covers every call.
+func chat(ctx context.Context, plugin llm.Plugin, model string, messages []llm.Message, tools []tool, env toolEnv) (*llm.ChatResponse, error) {
+	specs := make([]llm.Tool, 0, len(tools))
+	for _, t := range tools {
+		specs = append(specs, t.Tool)
+	}
+	var usage llm.Usage
+	for round := 0; ; round++ {
+		req := &llm.ChatRequest{Model: model, Messages: messages}
+		if round < maxToolRounds {
+			req.Tools = specs
+		}
+		resp, err := plugin.Chat(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		usage.Add(resp.Usage)
+		if len(resp.ToolCalls) == 0 || len(req.Tools) == 0 {
+			resp.Usage = usage
+			return resp, nil
+		}
+		messages = append(messages, llm.Message{Role: "assistant", Content: resp.Content, ToolCalls: resp.ToolCalls})
+		for _, call := range resp.ToolCalls {
+			messages = append(messages, llm.Message{Role: "tool", ToolCallID: call.ID, Content: runTool(ctx, tools, env, call)})
+		}
+	}
+}
+
+// runTool executes call and returns its result; failures are reported to the model as text.
+func runTool(ctx context.Context, tools []tool, env toolEnv, call llm.ToolCall) string {
+	for _, t := range tools {
+		if t.Name != call.Name {
+			continue
+		}
+		args := json.RawMessage(call.Arguments)
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		out, err := t.run(ctx, env, args)
+		if err != nil {
+			slog.Warn("agent: tool failed", "tool", call.Name, "agent", env.AgentID, "err", err)
+			return "error: " + err.Error()
+		}
+		slog.Debug("agent: tool", "tool", call.Name, "agent", env.AgentID)
+		return out
+	}
+	return fmt.Sprintf("error: unknown tool %q", call.Name)
+}
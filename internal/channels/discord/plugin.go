@@ -9,6 +9,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/openclaw/openclaw-go/internal/channels"
+	"github.com/openclaw/openclaw-go/internal/commands"
 	discordpkg "github.com/openclaw/openclaw-go/internal/discord"
 )
 
@@ -49,14 +50,17 @@ func (Plugin) StartAccount(ctx channels.StartAccountContext) error {
 	s.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages |
 		discordgo.IntentsMessageContent | discordgo.IntentsGuilds
 
+	channelCache := &discordpkg.ChannelCache{Session: s}
 	handler := &discordpkg.MessageHandler{
-		Cfg:             ctx.Cfg,
+		Config:          ctx.Runtime.Config,
 		DiscordCfg:      &discordpkg.DiscordConfig{AllowBots: false, DMPolicy: "open"},
 		AccountID:       ctx.AccountID,
 		BotUserID:       "",
 		DMEnabled:       true,
 		GroupDMEnabled:  true,
 		GuildEntries:    nil,
+		Channels:        channelCache,
+		Sessions:        ctx.Runtime.Sessions,
 		DispatchInbound: ctx.Runtime.DispatchInbound,
 	}
 
@@ -64,13 +68,44 @@ func (Plugin) StartAccount(ctx channels.StartAccountContext) error {
 		handler.Handle(s, m)
 	})
 
+	interactions := &discordpkg.InteractionHandler{
+		Config:          ctx.Runtime.Config,
+		DiscordCfg:      handler.DiscordCfg,
+		AccountID:       ctx.AccountID,
+		DMEnabled:       handler.DMEnabled,
+		GroupDMEnabled:  handler.GroupDMEnabled,
+		GuildEntries:    handler.GuildEntries,
+		Channels:        channelCache,
+		DispatchInbound: ctx.Runtime.DispatchInbound,
+	}
+	s.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		interactions.Handle(s, i)
+	})
+	s.AddHandler(func(s *discordgo.Session, c *discordgo.ChannelUpdate) { channelCache.Forget(c.ID) })
+	s.AddHandler(func(s *discordgo.Session, c *discordgo.ChannelDelete) { channelCache.Forget(c.ID) })
+	s.AddHandler(func(s *discordgo.Session, t *discordgo.ThreadUpdate) {
+		channelCache.Forget(t.ID)
+		handler.HandleThreadUpdate(t.ID, t.ThreadMetadata != nil && t.ThreadMetadata.Archived)
+	})
+	s.AddHandler(func(s *discordgo.Session, t *discordgo.ThreadDelete) {
+		channelCache.Forget(t.ID)
+		handler.HandleThreadUpdate(t.ID, true)
+	})
+
 	if err := s.Open(); err != nil {
 		slog.Error("discord: open connection", "err", err)
 		return err
 	}
 	if s.State != nil && s.State.User != nil {
 		handler.BotUserID = s.State.User.ID
+		interactions.BotUserID = handler.BotUserID
 		slog.Info("discord: logged in", "account", ctx.AccountID, "bot_id", handler.BotUserID)
+
+		if ctx.Runtime.Commands != nil && commands.NativeEnabled(ctx.Cfg) {
+			if err := discordpkg.RegisterNativeCommands(s, s.State.User.ID, ctx.Runtime.Commands, ctx.Cfg); err != nil {
+				slog.Warn("discord: register native commands", "err", err)
+			}
+		}
 	}
 
 	slog.Info("discord: monitor running, press Ctrl+C to stop")
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+)
+
+// IsOwner reports whether the sender is listed in commands.owner_ids.
+func IsOwner(cfg *config.Config, provider, senderID string) bool {
+	if cfg == nil {
+		return false
+	}
+	return matchesSender(cfg.Commands.OwnerIDs, provider, senderID)
+}
+
+// SenderAllowed reports whether the sender may run commands at all (commands.allow_from / allow_roles).
+// Channels use it to set MsgContext.CommandAuthorized.
+func SenderAllowed(cfg *config.Config, provider, senderID string, roles []string) bool {
+	if cfg == nil {
+		return true
+	}
+	c := cfg.Commands
+	if len(c.AllowFrom) == 0 && len(c.AllowRoles) == 0 {
+		return true
+	}
+	if IsOwner(cfg, provider, senderID) {
+		return true
+	}
+	return matchesSender(c.AllowFrom, provider, senderID) || hasAnyRole(c.AllowRoles, roles)
+}
+
+// Authorized reports whether the sender of msgCtx may run cmd.
+// Owners may run everything; otherwise the sender must be command-authorized, the command must not be
+// owner-only, and when the policy lists users or roles the sender must match one of them.
+func Authorized(cfg *config.Config, cmd *Command, msgCtx *inbound.MsgContext) bool {
+	if IsOwner(cfg, msgCtx.Provider, msgCtx.SenderId) {
+		return true
+	}
+	if !msgCtx.CommandAuthorized {
+		return false
+	}
+	ownerOnly := cmd.OwnerOnly
+	var policy config.CommandPolicy
+	if cfg != nil {
+		policy = cfg.Commands.Commands[cmd.Name]
+	}
+	if policy.OwnerOnly != nil {
+		ownerOnly = *policy.OwnerOnly
+	}
+	if ownerOnly {
+		return false
+	}
+	if len(policy.Users) == 0 && len(policy.Roles) == 0 {
+		return true
+	}
+	return matchesSender(policy.Users, msgCtx.Provider, msgCtx.SenderId) || hasAnyRole(policy.Roles, msgCtx.SenderRoles)
+}
+
+// matchesSender matches "123" or "discord:123" style entries against the sender.
+func matchesSender(list []string, provider, senderID string) bool {
+	if senderID == "" {
+		return false
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for _, raw := range list {
+		entry := strings.TrimSpace(raw)
+		if entry == "*" || entry == senderID {
+			return true
+		}
+		if p, id, ok := strings.Cut(entry, ":"); ok && strings.ToLower(p) == provider && id == senderID {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyRole(allowed, roles []string) bool {
+	for _, a := range allowed {
+		a = strings.TrimSpace(a)
+		for _, r := range roles {
+			if a != "" && a == r {
+				return true
+			}
+		}
+	}
+	return false
+}
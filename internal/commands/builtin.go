@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/routing"
+	"github.com/openclaw/openclaw-go/internal/session"
+)
+
+// RegisterBuiltins registers /help, /status, /reset, /model, /agent, /usage and /route.
+func RegisterBuiltins(r *Router) {
+	r.Register(Command{Name: "help", Description: "List available commands", Handler: helpCommand})
+	r.Register(Command{Name: "status", Description: "Show agent, session and model for this conversation", Handler: statusCommand})
+	r.Register(Command{Name: "reset", Description: "Start a fresh session (clears history)", Handler: resetCommand})
+	r.Register(Command{
+		Name:           "model",
+		Description:    "Show or set the model for this session",
+		ArgName:        "model",
+		ArgDescription: "Model id, or \"default\" to clear the override",
+		Handler:        modelCommand,
+	})
+	r.Register(Command{
+		Name:           "agent",
+		Description:    "Show or switch the agent for this session",
+		ArgName:        "agent",
+		ArgDescription: "Agent id from agents.list, or \"default\" to clear the override",
+		Handler:        agentCommand,
+	})
+	r.Register(Command{Name: "usage", Description: "Show token usage for this session", Handler: usageCommand})
+	r.Register(Command{
+		Name:        "route",
+		Description: "Explain which binding routes this conversation to its agent",
+		OwnerOnly:   true,
+		Handler:     routeCommand,
+	})
+}
+
+func helpCommand(_ context.Context, inv *Invocation) (string, error) {
+	var b strings.Builder
+	b.WriteString("Commands:")
+	for _, c := range inv.Router.Commands() {
+		if !CommandEnabled(inv.Env.Cfg, c.Name) || !Authorized(inv.Env.Cfg, &c, inv.Msg) {
+			continue
+		}
+		b.WriteString("\n/" + c.Name)
+		if c.ArgName != "" {
+			b.WriteString(" [" + c.ArgName + "]")
+		}
+		b.WriteString(" — " + c.Description)
+	}
+	return b.String(), nil
+}
+
+func statusCommand(_ context.Context, inv *Invocation) (string, error) {
+	agentID := inv.Msg.AgentID
+	model := defaultModel(inv)
+	var extra []string
+	if s := inv.Env.Sessions; s != nil && inv.Msg.SessionKey != "" {
+		e := s.Get(inv.Msg.SessionKey)
+		if e.AgentOverride != "" {
+			agentID = e.AgentOverride + " (override)"
+		}
+		if e.ModelOverride != "" {
+			model = e.ModelOverride + " (override)"
+		}
+		extra = append(extra,
+			"Session ID: "+e.SessionID,
+			fmt.Sprintf("History: %d messages", len(e.History)))
+	}
+	lines := []string{
+		"Agent: " + orDash(agentID),
+		"Session: " + orDash(inv.Msg.SessionKey),
+		"Chat type: " + orDash(inv.Msg.ChatType),
+	}
+	lines = append(lines, extra...)
+	lines = append(lines, "Model: "+orDash(model))
+	return strings.Join(lines, "\n"), nil
+}
+
+func resetCommand(_ context.Context, inv *Invocation) (string, error) {
+	if inv.Env.Sessions == nil || inv.Msg.SessionKey == "" {
+		return "Sessions are not enabled.", nil
+	}
+	inv.Env.Sessions.Reset(inv.Msg.SessionKey, session.ResetManual, false)
+	return "Session reset. Starting fresh.", nil
+}
+
+func modelCommand(_ context.Context, inv *Invocation) (string, error) {
+	if inv.Env.Sessions == nil || inv.Msg.SessionKey == "" {
+		return "Sessions are not enabled.", nil
+	}
+	arg := strings.TrimSpace(inv.Args)
+	switch {
+	case arg == "":
+		e := inv.Env.Sessions.Get(inv.Msg.SessionKey)
+		if e.ModelOverride != "" {
+			return fmt.Sprintf("Model: %s (override; default %s)", e.ModelOverride, orDash(defaultModel(inv))), nil
+		}
+		return "Model: " + orDash(defaultModel(inv)), nil
+	case isClear(arg):
+		inv.Env.Sessions.Update(inv.Msg.SessionKey, func(e *session.Entry) { e.ModelOverride = "" })
+		return "Model override cleared; using " + orDash(defaultModel(inv)) + ".", nil
+	case strings.ContainsAny(arg, " \t\n"):
+		return "Usage: /model <model-id>", nil
+	}
+	inv.Env.Sessions.Update(inv.Msg.SessionKey, func(e *session.Entry) { e.ModelOverride = arg })
+	return "Model set to " + arg + " for this session.", nil
+}
+
+func agentCommand(_ context.Context, inv *Invocation) (string, error) {
+	if inv.Env.Sessions == nil || inv.Msg.SessionKey == "" {
+		return "Sessions are not enabled.", nil
+	}
+	var ids []string
+	if inv.Env.Cfg != nil {
+		for _, a := range inv.Env.Cfg.Agents.List {
+			if id := strings.TrimSpace(a.ID); id != "" {
+				ids = append(ids, routing.NormalizeAgentId(id))
+			}
+		}
+	}
+	arg := strings.TrimSpace(inv.Args)
+	switch {
+	case arg == "":
+		current := inv.Msg.AgentID
+		if e := inv.Env.Sessions.Get(inv.Msg.SessionKey); e.AgentOverride != "" {
+			current = e.AgentOverride + " (override)"
+		}
+		return fmt.Sprintf("Agent: %s\nAvailable: %s", orDash(current), orDash(strings.Join(ids, ", "))), nil
+	case isClear(arg):
+		inv.Env.Sessions.Update(inv.Msg.SessionKey, func(e *session.Entry) { e.AgentOverride = "" })
+		inv.Env.Sessions.Reset(inv.Msg.SessionKey, session.ResetAgentSwitch, false)
+		return "Agent override cleared; session reset.", nil
+	}
+	want := routing.NormalizeAgentId(arg)
+	for _, id := range ids {
+		if id == want {
+			inv.Env.Sessions.Update(inv.Msg.SessionKey, func(e *session.Entry) { e.AgentOverride = id })
+			inv.Env.Sessions.Reset(inv.Msg.SessionKey, session.ResetAgentSwitch, false)
+			return "Switched to agent " + id + "; session reset.", nil
+		}
+	}
+	return fmt.Sprintf("Unknown agent %q. Available: %s", arg, orDash(strings.Join(ids, ", "))), nil
+}
+
+func usageCommand(_ context.Context, inv *Invocation) (string, error) {
+	if inv.Env.Sessions == nil || inv.Msg.SessionKey == "" {
+		return "Sessions are not enabled.", nil
+	}
+	u := inv.Env.Sessions.Get(inv.Msg.SessionKey).Usage
+	return fmt.Sprintf("Turns: %d\nInput tokens: %d\nOutput tokens: %d\nTotal tokens: %d",
+		u.Turns, u.InputTokens, u.OutputTokens, u.InputTokens+u.OutputTokens), nil
+}
+
+func defaultModel(inv *Invocation) string {
+	if inv.Env.Cfg == nil {
+		return ""
+	}
+	return inv.Env.Cfg.Agents.Defaults.DefaultModel
+}
+
+func isClear(arg string) bool {
+	switch strings.ToLower(arg) {
+	case "default", "reset", "clear", "off":
+		return true
+	}
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// routeCommand re-resolves the message's route against the current config and lists every binding
+// with the reason it was or was not used.
+func routeCommand(_ context.Context, inv *Invocation) (string, error) {
+	if inv.Msg.RouteInput == nil {
+		return "No routing details for this message.", nil
+	}
+	input := *inv.Msg.RouteInput
+	input.Cfg = inv.Env.Cfg
+	text := routing.ExplainAgentRoute(input).String()
+	if s := inv.Env.Sessions; s != nil && inv.Msg.SessionKey != "" {
+		if o := s.Get(inv.Msg.SessionKey).AgentOverride; o != "" {
+			text += "override:     " + o + " (set with /agent; replies come from this agent)\n"
+		}
+	}
+	return "```\n" + text + "```", nil
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/session"
+)
+
+var nameRe = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Command is a chat command such as /reset. Commands are channel-agnostic: they only see
+// the MsgContext and Env, so channels can expose them as text or native commands.
+type Command struct {
+	Name        string
+	Description string
+	// ArgName names the single free-form argument (e.g. "model"); empty if the command takes none.
+	ArgName        string
+	ArgDescription string
+	// OwnerOnly restricts the command to commands.owner_ids unless overridden in config.
+	OwnerOnly bool
+	Handler   Handler
+}
+
+// Handler runs a command and returns the reply text.
+type Handler func(ctx context.Context, inv *Invocation) (string, error)
+
+// Invocation is a parsed command call.
+type Invocation struct {
+	Name   string
+	Args   string
+	Msg    *inbound.MsgContext
+	Env    Env
+	Router *Router
+}
+
+// Env carries the process state commands read or mutate.
+type Env struct {
+	Cfg      *config.Config
+	Sessions *session.Store
+}
+
+// Router parses command messages and dispatches them to registered commands.
+type Router struct {
+	mu   sync.RWMutex
+	cmds map[string]*Command
+}
+
+// NewRouter returns an empty router.
+func NewRouter() *Router {
+	return &Router{cmds: make(map[string]*Command)}
+}
+
+// Register adds or replaces a command. Names are lowercased; invalid names panic.
+func (r *Router) Register(c Command) {
+	c.Name = strings.ToLower(strings.TrimSpace(c.Name))
+	if !nameRe.MatchString(c.Name) {
+		panic("commands: invalid command name " + c.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cmds[c.Name] = &c
+}
+
+// Lookup returns the command by name, or nil.
+func (r *Router) Lookup(name string) *Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cmds[strings.ToLower(name)]
+}
+
+// Commands returns all registered commands sorted by name.
+func (r *Router) Commands() []Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Command, 0, len(r.cmds))
+	for _, c := range r.cmds {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Parse splits "/name args" into name and args. ok is false when body is not a command.
+func Parse(body string) (name, args string, ok bool) {
+	body = strings.TrimSpace(body)
+	if !strings.HasPrefix(body, "/") {
+		return "", "", false
+	}
+	body = body[1:]
+	name, args, _ = strings.Cut(body, " ")
+	if i := strings.IndexByte(name, '\n'); i >= 0 {
+		name, args = name[:i], name[i+1:]+" "+args
+	}
+	name = strings.ToLower(name)
+	if !nameRe.MatchString(name) {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(args), true
+}
+
+// Handle runs the command in msgCtx.BodyForCommands, if any. handled is false when the message is
+// not a known, enabled command and should go to the agent instead; native commands are always
+// handled, with a "disabled" reply when they cannot run.
+func (r *Router) Handle(ctx context.Context, env Env, msgCtx *inbound.MsgContext) (reply string, handled bool, err error) {
+	name, args, ok := Parse(msgCtx.BodyForCommands)
+	var cmd *Command
+	if ok && (env.Cfg == nil || enabled(env.Cfg.Commands.Enabled)) && CommandEnabled(env.Cfg, name) {
+		cmd = r.Lookup(name)
+	}
+	if cmd == nil {
+		if msgCtx.NativeCommand {
+			return "This command is disabled.", true, nil
+		}
+		return "", false, nil
+	}
+	if !Authorized(env.Cfg, cmd, msgCtx) {
+		return "You are not allowed to use /" + name + ".", true, nil
+	}
+	reply, err = cmd.Handler(ctx, &Invocation{Name: name, Args: args, Msg: msgCtx, Env: env, Router: r})
+	return reply, true, err
+}
+
+// NativeEnabled reports whether commands should be registered as channel-native commands.
+func NativeEnabled(cfg *config.Config) bool {
+	if cfg == nil {
+		return true
+	}
+	return enabled(cfg.Commands.Enabled) && enabled(cfg.Commands.Native)
+}
+
+// CommandEnabled reports whether the named command is enabled by config.
+func CommandEnabled(cfg *config.Config, name string) bool {
+	if cfg == nil {
+		return true
+	}
+	p, ok := cfg.Commands.Commands[name]
+	return !ok || enabled(p.Enabled)
+}
+
+func enabled(b *bool) bool {
+	return b == nil || *b
+}
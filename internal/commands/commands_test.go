@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/session"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		body       string
+		name, args string
+		ok         bool
+	}{
+		{"/reset", "reset", "", true},
+		{"  /model gpt-4o  ", "model", "gpt-4o", true},
+		{"/Model GPT-4o", "model", "GPT-4o", true},
+		{"/agent  support bot", "agent", "support bot", true},
+		{"/model\nclaude-3", "model", "claude-3", true},
+		{"/my_cmd-2", "my_cmd-2", "", true},
+		{"reset", "", "", false},
+		{"hello /reset", "", "", false},
+		{"/", "", "", false},
+		{"/ reset", "", "", false},
+		{"/re.set", "", "", false},
+		{"/" + strings.Repeat("a", 33), "", "", false},
+	}
+	for _, tt := range tests {
+		name, args, ok := Parse(tt.body)
+		if name != tt.name || args != tt.args || ok != tt.ok {
+			t.Errorf("Parse(%q) = %q, %q, %v; want %q, %q, %v", tt.body, name, args, ok, tt.name, tt.args, tt.ok)
+		}
+	}
+}
+
+func TestSenderAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands config.CommandsConfig
+		provider string
+		sender   string
+		roles    []string
+		want     bool
+	}{
+		{name: "no allow list", sender: "1", want: true},
+		{name: "allow_from id", commands: config.CommandsConfig{AllowFrom: []string{"1"}}, sender: "1", want: true},
+		{name: "allow_from provider id", commands: config.CommandsConfig{AllowFrom: []string{"Discord:1"}}, provider: "discord", sender: "1", want: true},
+		{name: "allow_from other provider", commands: config.CommandsConfig{AllowFrom: []string{"telegram:1"}}, provider: "discord", sender: "1"},
+		{name: "allow_from wildcard", commands: config.CommandsConfig{AllowFrom: []string{"*"}}, sender: "2", want: true},
+		{name: "allow_from miss", commands: config.CommandsConfig{AllowFrom: []string{"1"}}, sender: "2"},
+		{name: "allow_roles", commands: config.CommandsConfig{AllowRoles: []string{"mods"}}, sender: "2", roles: []string{"x", "mods"}, want: true},
+		{name: "allow_roles miss", commands: config.CommandsConfig{AllowRoles: []string{"mods"}}, sender: "2", roles: []string{"x"}},
+		{name: "owner bypasses", commands: config.CommandsConfig{OwnerIDs: []string{"9"}, AllowFrom: []string{"1"}}, sender: "9", want: true},
+		{name: "no sender", commands: config.CommandsConfig{AllowFrom: []string{"*"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Commands: tt.commands}
+			if got := SenderAllowed(cfg, tt.provider, tt.sender, tt.roles); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name       string
+		policy     *config.CommandPolicy
+		ownerOnly  bool
+		sender     string
+		roles      []string
+		authorized bool
+		want       bool
+	}{
+		{name: "authorized", sender: "1", authorized: true, want: true},
+		{name: "not command-authorized", sender: "1"},
+		{name: "owner not command-authorized", sender: "9", want: true},
+		{name: "owner-only", ownerOnly: true, sender: "1", authorized: true},
+		{name: "owner-only owner", ownerOnly: true, sender: "9", want: true},
+		{name: "policy lifts owner-only", ownerOnly: true, policy: &config.CommandPolicy{OwnerOnly: &no}, sender: "1", authorized: true, want: true},
+		{name: "policy sets owner-only", policy: &config.CommandPolicy{OwnerOnly: &yes}, sender: "1", authorized: true},
+		{name: "policy users", policy: &config.CommandPolicy{Users: []string{"discord:1"}}, sender: "1", authorized: true, want: true},
+		{name: "policy users miss", policy: &config.CommandPolicy{Users: []string{"2"}}, sender: "1", authorized: true},
+		{name: "policy roles", policy: &config.CommandPolicy{Roles: []string{"mods"}}, sender: "1", roles: []string{"mods"}, authorized: true, want: true},
+		{name: "policy roles miss", policy: &config.CommandPolicy{Roles: []string{"mods"}}, sender: "1", authorized: true},
+		{name: "policy roles owner", policy: &config.CommandPolicy{Roles: []string{"mods"}}, sender: "9", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Commands: config.CommandsConfig{OwnerIDs: []string{"discord:9"}}}
+			if tt.policy != nil {
+				cfg.Commands.Commands = map[string]config.CommandPolicy{"cmd": *tt.policy}
+			}
+			msg := &inbound.MsgContext{Provider: "discord", SenderId: tt.sender, SenderRoles: tt.roles, CommandAuthorized: tt.authorized}
+			if got := Authorized(cfg, &Command{Name: "cmd", OwnerOnly: tt.ownerOnly}, msg); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// handle runs body through a router with the builtins and an echo command, from sender "1".
+func handle(t *testing.T, cfg *config.Config, sessions *session.Store, body string) (string, bool) {
+	t.Helper()
+	r := NewRouter()
+	RegisterBuiltins(r)
+	r.Register(Command{Name: "echo", Handler: func(_ context.Context, inv *Invocation) (string, error) {
+		return "echo:" + inv.Args, nil
+	}})
+	msg := &inbound.MsgContext{
+		BodyForCommands:   body,
+		SessionKey:        "agent:main:main",
+		AgentID:           "main",
+		Provider:          "discord",
+		SenderId:          "1",
+		CommandAuthorized: true,
+	}
+	reply, handled, err := r.Handle(context.Background(), Env{Cfg: cfg, Sessions: sessions}, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reply, handled
+}
+
+func TestRouterHandle(t *testing.T) {
+	no := false
+	tests := []struct {
+		name     string
+		commands config.CommandsConfig
+		body     string
+		want     string
+		handled  bool
+	}{
+		{name: "args", body: "/echo hello world", want: "echo:hello world", handled: true},
+		{name: "case-insensitive", body: "/ECHO hi", want: "echo:hi", handled: true},
+		{name: "unknown command", body: "/nope"},
+		{name: "not a command", body: "echo hi"},
+		{name: "commands disabled", commands: config.CommandsConfig{Enabled: &no}, body: "/echo hi"},
+		{name: "command disabled", commands: config.CommandsConfig{Commands: map[string]config.CommandPolicy{"echo": {Enabled: &no}}}, body: "/echo hi"},
+		{name: "owner-only", body: "/route", want: "You are not allowed to use /route.", handled: true},
+		{name: "policy users", commands: config.CommandsConfig{Commands: map[string]config.CommandPolicy{"echo": {Users: []string{"2"}}}}, body: "/echo hi", want: "You are not allowed to use /echo.", handled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, handled := handle(t, &config.Config{Commands: tt.commands}, nil, tt.body)
+			if reply != tt.want || handled != tt.handled {
+				t.Errorf("got %q, %v; want %q, %v", reply, handled, tt.want, tt.handled)
+			}
+		})
+	}
+}
+
+func TestRouterHandleNative(t *testing.T) {
+	no := false
+	r := NewRouter()
+	RegisterBuiltins(r)
+	tests := []struct {
+		name     string
+		commands config.CommandsConfig
+		body     string
+		want     string
+	}{
+		{name: "unknown", body: "/nope", want: "This command is disabled."},
+		{name: "commands disabled", commands: config.CommandsConfig{Enabled: &no}, body: "/reset", want: "This command is disabled."},
+		{name: "command disabled", commands: config.CommandsConfig{Commands: map[string]config.CommandPolicy{"reset": {Enabled: &no}}}, body: "/reset", want: "This command is disabled."},
+		{name: "enabled", body: "/reset", want: "Sessions are not enabled."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &inbound.MsgContext{BodyForCommands: tt.body, NativeCommand: true, CommandAuthorized: true}
+			reply, handled, err := r.Handle(context.Background(), Env{Cfg: &config.Config{Commands: tt.commands}}, msg)
+			if err != nil || !handled || reply != tt.want {
+				t.Errorf("got %q, %v, %v; want %q, handled", reply, handled, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetCommand(t *testing.T) {
+	s := session.NewStore()
+	before := s.Append("agent:main:main", llm.Usage{}, llm.Message{Role: "user", Content: "hi"})
+	s.Update("agent:main:main", func(e *session.Entry) { e.ModelOverride = "m" })
+
+	if reply, _ := handle(t, &config.Config{}, s, "/reset"); reply != "Session reset. Starting fresh." {
+		t.Errorf("reply = %q", reply)
+	}
+	e := s.Get("agent:main:main")
+	if e.SessionID == before.SessionID || len(e.History) != 0 {
+		t.Errorf("got session %s with %d messages, want a new empty session", e.SessionID, len(e.History))
+	}
+	if e.ModelOverride != "m" {
+		t.Errorf("model override = %q, want it kept across /reset", e.ModelOverride)
+	}
+
+	if reply, _ := handle(t, &config.Config{}, nil, "/reset"); reply != "Sessions are not enabled." {
+		t.Errorf("without sessions: reply = %q", reply)
+	}
+}
+
+func TestModelCommand(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Agents.Defaults.DefaultModel = "base"
+	s := session.NewStore()
+	steps := []struct {
+		body     string
+		want     string
+		override string
+	}{
+		{"/model", "Model: base", ""},
+		{"/model gpt-4o", "Model set to gpt-4o for this session.", "gpt-4o"},
+		{"/model", "Model: gpt-4o (override; default base)", "gpt-4o"},
+		{"/model two words", "Usage: /model <model-id>", "gpt-4o"},
+		{"/model Default", "Model override cleared; using base.", ""},
+	}
+	for _, step := range steps {
+		if reply, _ := handle(t, cfg, s, step.body); reply != step.want {
+			t.Errorf("%s: reply = %q, want %q", step.body, reply, step.want)
+		}
+		if got := s.Get("agent:main:main").ModelOverride; got != step.override {
+			t.Errorf("%s: override = %q, want %q", step.body, got, step.override)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package config
 
 import (
-	"bufio"
+	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 
+	"github.com/openclaw/openclaw-go/internal/tmpl"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,7 +17,17 @@ import (
 type Config struct {
 	Agents   AgentsConfig   `yaml:"agents"`
 	Bindings []AgentBinding `yaml:"bindings"`
+	Routing  RoutingConfig  `yaml:"routing,omitempty"`
 	Session  SessionConfig  `yaml:"session"`
+	Commands CommandsConfig `yaml:"commands"`
+	Channels ChannelsConfig `yaml:"channels"`
+	Media    MediaConfig    `yaml:"media"`
+	Memory   MemoryConfig   `yaml:"memory"`
+	// Providers holds per-plugin settings (credentials, endpoint) keyed by LLM / embeddings plugin id.
+	Providers map[string]ProviderConfig `yaml:"providers,omitempty"`
+
+	// src is the merged document, kept to locate Validate issues; nil for Default().
+	src *source
 }
 
 // AgentsConfig holds agent defaults.
@@ -27,47 +41,322 @@ type AgentsDefaults struct {
 	DefaultModel string `yaml:"default_model"`
 	// LLMProvider 指定使用的 LLM 插件 id（如 "kimi"），为空则不调用大模型。
 	LLMProvider string `yaml:"llm_provider"`
+	// Envelope controls the header prepended to each inbound message for all agents.
+	Envelope EnvelopeConfig `yaml:"envelope,omitempty"`
+	// SystemPrompt is the system prompt template for agents without their own (empty = built-in prompt).
+	SystemPrompt *tmpl.Template `yaml:"system_prompt,omitempty"`
+	// Timezone is the IANA timezone for template dates and envelope times; empty means the host's local time.
+	Timezone string `yaml:"timezone,omitempty"`
+	// Vars are custom template variables ({{.Vars.name}}) for all agents.
+	Vars map[string]string `yaml:"vars,omitempty"`
+	// WorkspaceRoot holds agent workspaces (<root>/<agent id>/PERSONA.md, ...); empty means ~/.openclaw/agents.
+	WorkspaceRoot string `yaml:"workspace_root,omitempty"`
+	// WorkspaceMaxChars caps each workspace file in the system prompt (default 8000).
+	WorkspaceMaxChars int `yaml:"workspace_max_chars,omitempty"`
+	// Delegation limits agents consulting each other (see AgentEntry.Delegates).
+	Delegation DelegationConfig `yaml:"delegation,omitempty"`
+}
+
+// DelegationConfig limits agent-to-agent delegation.
+type DelegationConfig struct {
+	// MaxDepth bounds nested delegation (a asks b, which asks c counts 2); default 2.
+	MaxDepth int `yaml:"max_depth,omitempty"`
+	// TimeoutSeconds bounds each delegated answer, nested ones included; default 120.
+	TimeoutSeconds int `yaml:"timeout_seconds,omitempty"`
+	// Handoff offers the agent_handoff tool, which passes the rest of the conversation to a
+	// delegate (default true).
+	Handoff *bool `yaml:"handoff,omitempty"`
 }
 
 // AgentEntry represents a single agent in the list.
 type AgentEntry struct {
 	ID string `yaml:"id"`
+	// Envelope overrides agents.defaults.envelope field by field.
+	Envelope *EnvelopeConfig `yaml:"envelope,omitempty"`
+	// SystemPrompt, Timezone and Vars override the defaults; Vars are merged key by key.
+	SystemPrompt *tmpl.Template    `yaml:"system_prompt,omitempty"`
+	Timezone     string            `yaml:"timezone,omitempty"`
+	Vars         map[string]string `yaml:"vars,omitempty"`
+	// Workspace overrides the agent's workspace directory.
+	Workspace string `yaml:"workspace,omitempty"`
+	// Description tells agents that may delegate to this one what it is for.
+	Description string `yaml:"description,omitempty"`
+	// Delegates are the agents this one may consult or hand off to; "*" allows every other agent.
+	Delegates []string `yaml:"delegates,omitempty"`
+}
+
+// EnvelopeConfig controls the header the agent sees before each inbound message
+// (sender, conversation, chat type and time), so it can tell speakers in shared sessions apart.
+type EnvelopeConfig struct {
+	// Enabled adds the header; nil means enabled.
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Timestamp includes the message time; nil means included.
+	Timestamp *bool `yaml:"timestamp,omitempty"`
+	// Timezone is an IANA name used to format the time; empty means the agent's timezone.
+	Timezone string `yaml:"timezone,omitempty"`
+	// Template replaces the built-in header, e.g. "[{{.SenderName}} in {{.Conversation}} at {{.Time}}]".
+	Template *tmpl.Template `yaml:"template,omitempty"`
+}
+
+// Agent returns the agents.list entry for id (case-insensitive).
+func (c *Config) Agent(id string) (AgentEntry, bool) {
+	if c == nil {
+		return AgentEntry{}, false
+	}
+	id = strings.TrimSpace(id)
+	for _, a := range c.Agents.List {
+		if strings.EqualFold(strings.TrimSpace(a.ID), id) {
+			return a, true
+		}
+	}
+	return AgentEntry{}, false
 }
 
 // AgentBinding binds a channel/peer/guild to an agent.
 type AgentBinding struct {
-	AgentID string        `yaml:"agent_id"`
-	Match   BindingMatch  `yaml:"match"`
+	AgentID string       `yaml:"agent_id"`
+	Match   BindingMatch `yaml:"match"`
+	// Priority orders bindings that match the same message: higher wins, ties keep file order.
+	Priority int `yaml:"priority,omitempty"`
+}
+
+// RoutingConfig controls how bindings are resolved.
+type RoutingConfig struct {
+	// Mode is "tiered" (default: the most specific matching binding wins) or "first-match" (the first
+	// matching binding in file order wins, and an empty account_id matches only the default account).
+	Mode string `yaml:"mode,omitempty"`
 }
 
 // BindingMatch defines matching criteria.
+//
+// ID fields (peer.id, peer.ids, channel_ids, parent, guild_id, team_id) take an exact id, a glob
+// ("12*", "?" and [...] as in path.Match) or a regular expression prefixed with "re:" that must
+// match the whole id.
 type BindingMatch struct {
-	Channel   string `yaml:"channel"`
-	AccountID string `yaml:"account_id"`
-	Peer      *struct {
-		Kind string `yaml:"kind"`
-		ID   string `yaml:"id"`
-	} `yaml:"peer,omitempty"`
-	GuildID string `yaml:"guild_id,omitempty"`
-	TeamID  string `yaml:"team_id,omitempty"`
+	Channel   string     `yaml:"channel"`
+	AccountID string     `yaml:"account_id"`
+	Peer      *PeerMatch `yaml:"peer,omitempty"`
+	GuildID   string     `yaml:"guild_id,omitempty"`
+	TeamID    string     `yaml:"team_id,omitempty"`
+
+	// Roles, ChannelIDs and Parent narrow a binding: when set they must all hold, on top of
+	// channel and account. On their own (no peer, guild or team) they are enough to match.
+
+	// Roles matches senders that have any of these role ids (Discord guild roles).
+	Roles []string `yaml:"roles,omitempty"`
+	// ChannelIDs matches messages in any of these channels, including threads under them.
+	ChannelIDs []string `yaml:"channel_ids,omitempty"`
+	// Parent matches only threads whose parent channel is this id.
+	Parent string `yaml:"parent,omitempty"`
+}
+
+// PeerMatch matches the conversation: a kind and one id (ID) or any of several (IDs).
+type PeerMatch struct {
+	Kind string   `yaml:"kind"`
+	ID   string   `yaml:"id,omitempty"`
+	IDs  []string `yaml:"ids,omitempty"`
 }
 
 // SessionConfig holds session settings.
 type SessionConfig struct {
-	DMScope       string            `yaml:"dm_scope"`
+	DMScope       string              `yaml:"dm_scope"`
 	IdentityLinks map[string][]string `yaml:"identity_links,omitempty"`
+	// StoreDir holds session state and transcripts; empty means ~/.openclaw/sessions.
+	StoreDir string `yaml:"store_dir,omitempty"`
+	// Reset is the default lifecycle policy; ResetByChatType overrides it per chat type
+	// ("direct", "group", "channel", "thread").
+	Reset           SessionResetConfig            `yaml:"reset,omitempty"`
+	ResetByChatType map[string]SessionResetConfig `yaml:"reset_by_chat_type,omitempty"`
+}
+
+// SessionResetConfig controls when a session is archived and a new one begins.
+type SessionResetConfig struct {
+	// IdleMinutes starts a new session after this many minutes without messages. nil inherits
+	// (off in session.reset); 0 turns the idle reset off, e.g. for one chat type.
+	IdleMinutes *int `yaml:"idle_minutes,omitempty"`
+	// DailyAt starts a new session once a day at "HH:MM" in Timezone ("off" disables).
+	DailyAt string `yaml:"daily_at,omitempty"`
+	// Timezone is an IANA name such as "Asia/Shanghai"; empty means the host's local time.
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+// MediaConfig limits inbound attachments passed to the agent.
+type MediaConfig struct {
+	// MaxFiles is the number of attachments read per message (default 4).
+	MaxFiles int `yaml:"max_files,omitempty"`
+	// MaxBytes skips attachments larger than this (default 8 MiB).
+	MaxBytes int64 `yaml:"max_bytes,omitempty"`
+	// MaxTextChars truncates inlined text files (default 20000).
+	MaxTextChars int `yaml:"max_text_chars,omitempty"`
+	// VisionModels lists models that accept images, in addition to those the LLM plugin recognizes.
+	VisionModels []string `yaml:"vision_models,omitempty"`
+	// SnippetFileLines sends fenced code blocks of at least this many lines in replies as file attachments (0 = off).
+	SnippetFileLines int `yaml:"snippet_file_lines,omitempty"`
+}
+
+// MemoryConfig controls long-term memory tools (memory_save / memory_search / memory_forget).
+type MemoryConfig struct {
+	// Enabled offers the memory tools to the model; nil means enabled.
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Dir holds memory files; empty means ~/.openclaw/memory.
+	Dir string `yaml:"dir,omitempty"`
+	// MaxEntries caps memories per agent and user (default 500); the oldest are dropped first.
+	MaxEntries int `yaml:"max_entries,omitempty"`
+	// Embeddings enables semantic search; without a provider memory_search matches keywords (BM25).
+	Embeddings EmbeddingsConfig `yaml:"embeddings,omitempty"`
+}
+
+// EmbeddingsConfig selects the embeddings provider for semantic memory search.
+type EmbeddingsConfig struct {
+	// Provider 为向量插件 id："openai"、"kimi" 或 "hash"（离线、确定性，仅匹配字词）；为空则不启用。
+	Provider string `yaml:"provider,omitempty"`
+	// Model 为向量模型名；为空则用插件默认（openai: text-embedding-3-small）。
+	Model string `yaml:"model,omitempty"`
+	// BaseURL 指向任意 OpenAI 兼容的 /embeddings 服务（如自建服务），覆盖插件默认地址。
+	BaseURL string `yaml:"base_url,omitempty"`
+	// APIKey 为 API Key 引用（env: / file: / secret:），优先于 APIKeyEnv 与 providers.<provider>.api_key。
+	APIKey Secret `yaml:"api_key,omitempty"`
+	// APIKeyEnv 为读取 API Key 的环境变量名，覆盖插件默认（OPENAI_API_KEY / MOONSHOT_API_KEY）。
+	APIKeyEnv string `yaml:"api_key_env,omitempty"`
+	// MinScore drops hits below this cosine similarity (default 0.3).
+	MinScore float64 `yaml:"min_score,omitempty"`
+}
+
+// ProviderConfig holds the credentials and endpoint of one LLM or embeddings plugin.
+type ProviderConfig struct {
+	// APIKey 为该插件的 API Key（如 env:MOONSHOT_API_KEY、secret:MOONSHOT_API_KEY）；为空则用插件默认的环境变量。
+	APIKey Secret `yaml:"api_key,omitempty"`
+	// BaseURL 覆盖插件默认的 API 地址。
+	BaseURL string `yaml:"base_url,omitempty"`
+}
+
+// ChannelsConfig holds per-channel-plugin settings.
+type ChannelsConfig struct {
+	Discord DiscordConfig `yaml:"discord"`
+}
+
+// DiscordConfig holds Discord behaviour settings, keyed by guild ID and channel ID.
+type DiscordConfig struct {
+	// Token is the bot token of the "default" account, used when Accounts is empty
+	// (the --token flag and DISCORD_TOKEN take precedence when set).
+	Token Secret `yaml:"token,omitempty"`
+	// Accounts runs several bots, keyed by account id (matched by bindings[].match.account_id).
+	Accounts map[string]DiscordAccountConfig `yaml:"accounts,omitempty"`
+	// TextChunkLimit caps each outgoing message in characters (default and maximum 2000).
+	TextChunkLimit int `yaml:"text_chunk_limit,omitempty"`
+	// AttachAboveChunks sends replies that would need more messages than this as a .md file (0 = never).
+	AttachAboveChunks int `yaml:"attach_above_chunks,omitempty"`
+	// MaxUploadBytes caps outgoing files; larger ones are sent as links or noted (default 8 MiB).
+	MaxUploadBytes int64 `yaml:"max_upload_bytes,omitempty"`
+	// Typing shows the typing indicator while the agent works; nil means enabled.
+	Typing *bool `yaml:"typing,omitempty"`
+	// StatusReactions adds queued / thinking / error reactions to the triggering message; nil means enabled.
+	StatusReactions *bool `yaml:"status_reactions,omitempty"`
+	// Guilds holds per-guild settings; the key "*" applies to guilds without their own entry.
+	Guilds map[string]DiscordGuildConfig `yaml:"guilds,omitempty"`
+}
+
+// DiscordAccountConfig is one Discord bot account.
+type DiscordAccountConfig struct {
+	// Token is required, e.g. env:DISCORD_TOKEN_WORK or file:/run/secrets/discord_work.
+	Token Secret `yaml:"token"`
+	// Enabled starts the account; nil means enabled.
+	Enabled *bool `yaml:"enabled,omitempty"`
+}
+
+// DiscordGuildConfig holds settings for one guild. Channel entries override guild settings
+// and threads inherit the entry of their parent channel.
+type DiscordGuildConfig struct {
+	DiscordChannelConfig `yaml:",inline"`
+	Channels             map[string]DiscordChannelConfig `yaml:"channels,omitempty"`
+}
+
+// DiscordChannelConfig holds per-channel settings. Unset fields inherit from the guild.
+type DiscordChannelConfig struct {
+	// RequireMention only answers messages that mention the bot (threads the bot created are exempt).
+	RequireMention *bool `yaml:"require_mention,omitempty"`
+	// AutoThread creates a thread for each new conversation started by a mention and replies there.
+	AutoThread *bool `yaml:"auto_thread,omitempty"`
+	// AutoArchiveMinutes is the auto-archive duration of created threads (60, 1440, 4320 or 10080).
+	AutoArchiveMinutes int `yaml:"auto_archive_minutes,omitempty"`
+	// ReplyToMode sends replies as Discord replies to the triggering message: "first" (first chunk, default),
+	// "all" (every chunk) or "off".
+	ReplyToMode string `yaml:"reply_to_mode,omitempty"`
+	// MentionOnReply pings the author of the message being replied to.
+	MentionOnReply *bool `yaml:"mention_on_reply,omitempty"`
+	// ReplyContext includes the message a user replies to in the prompt; nil means enabled.
+	ReplyContext *bool `yaml:"reply_context,omitempty"`
+	// HistoryLimit includes up to this many earlier channel messages in the prompt (0 = off, max 50).
+	HistoryLimit int `yaml:"history_limit,omitempty"`
+}
+
+// CommandsConfig controls chat commands (/reset, /model, ...) and their native registration.
+type CommandsConfig struct {
+	// Enabled turns command handling on; nil means enabled.
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Native registers commands as channel-native commands (Discord application commands); nil means enabled.
+	Native *bool `yaml:"native,omitempty"`
+	// OwnerIDs may run every command. Entries are sender IDs, optionally prefixed with the channel ("discord:123").
+	OwnerIDs []string `yaml:"owner_ids,omitempty"`
+	// AllowFrom and AllowRoles restrict who may run commands at all; both empty allows everyone.
+	AllowFrom  []string `yaml:"allow_from,omitempty"`
+	AllowRoles []string `yaml:"allow_roles,omitempty"`
+	// Commands holds per-command overrides keyed by command name (without the slash).
+	Commands map[string]CommandPolicy `yaml:"commands,omitempty"`
+}
+
+// CommandPolicy overrides authorization for a single command.
+type CommandPolicy struct {
+	Enabled   *bool `yaml:"enabled,omitempty"`
+	OwnerOnly *bool `yaml:"owner_only,omitempty"`
+	// Users and Roles, when set, limit the command to these sender IDs or role IDs (owners always pass).
+	Users []string `yaml:"users,omitempty"`
+	Roles []string `yaml:"roles,omitempty"`
 }
 
-// Load reads config from path (YAML). Falls back to empty config on error.
+// Load reads config from path (YAML) with the overlay named by OPENCLAW_ENV; see LoadEnv.
 func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
+	return LoadEnv(path, Getenv(EnvVar))
+}
+
+// LoadEnv reads config from path (YAML). A missing file yields Default() with a warning.
+//
+// Files listed under a top-level include key (relative to the file naming them) are merged
+// underneath it, and when env is set, path's overlay (openclaw.yaml -> openclaw.<env>.yaml) is
+// merged on top if it exists; see mergeNodes for the rules. ${VAR} references are expanded and
+// Secret fields resolved (see Secret). Unknown keys, type mismatches, syntax errors and missing
+// secrets are returned as Errors with their file, line and column.
+func LoadEnv(path, env string) (*Config, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		slog.Warn("config file not found, using defaults", "path", path)
 		return Default(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	l := newLoader(false)
+	root := l.layers(path, env)
+	if len(l.errs) > 0 {
+		return nil, l.errs
 	}
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+	if root != nil {
+		l.src.doc = root
+		if errs := decodeErrors(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, &cfg); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+	var errs Errors
+	resolveSecrets(reflect.ValueOf(&cfg).Elem(), "", &secretResolver{secretsPath: ResolveSecretsPath()}, l.src, &errs)
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			if errs[i].File != errs[j].File {
+				return errs[i].File < errs[j].File
+			}
+			return errs[i].Line < errs[j].Line
+		})
+		return nil, errs
 	}
+	cfg.src = l.src
 	return &cfg, nil
 }
 
@@ -90,7 +379,7 @@ func Default() *Config {
 
 // ResolveConfigPath returns path to config file (openclaw.yaml in home or cwd).
 func ResolveConfigPath() string {
-	if p := os.Getenv("OPENCLAW_CONFIG"); p != "" {
+	if p := Getenv("OPENCLAW_CONFIG"); p != "" {
 		return p
 	}
 	home, _ := os.UserHomeDir()
@@ -99,47 +388,3 @@ func ResolveConfigPath() string {
 	}
 	return "openclaw.yaml"
 }
-
-// ResolveSecretsPath returns path to secrets file (goopenclaw.secrets).
-// Order: OPENCLAW_SECRETS env > ./goopenclaw.secrets (cwd) > ~/.openclaw/goopenclaw.secrets.
-func ResolveSecretsPath() string {
-	if p := os.Getenv("OPENCLAW_SECRETS"); p != "" {
-		return p
-	}
-	if cwd, _ := os.Getwd(); cwd != "" {
-		return filepath.Join(cwd, "goopenclaw.secrets")
-	}
-	home, _ := os.UserHomeDir()
-	if home != "" {
-		return filepath.Join(home, ".openclaw", "goopenclaw.secrets")
-	}
-	return "goopenclaw.secrets"
-}
-
-// LoadSecrets reads path as KEY=VALUE lines (optional # comments) and sets each as os environment.
-// Used so DISCORD_TOKEN, MOONSHOT_API_KEY etc. can be read from file without committing secrets.
-func LoadSecrets(path string) error {
-	f, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	sc := bufio.NewScanner(f)
-	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		idx := strings.Index(line, "=")
-		if idx <= 0 {
-			continue
-		}
-		key := strings.TrimSpace(line[:idx])
-		val := strings.TrimSpace(line[idx+1:])
-		if key == "" {
-			continue
-		}
-		os.Setenv(key, val)
-	}
-	return sc.Err()
-}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change is one difference between two configs.
+type Change struct {
+	Path string // e.g. "bindings[1].agent_id"
+	Kind string // "added", "removed" or "changed"
+}
+
+func (c Change) String() string {
+	return c.Kind + " " + c.Path
+}
+
+// Diff lists the settings that differ between old and new, as YAML paths. Values are not included,
+// so the result is safe to log. A secret whose reference is unchanged but resolves to a new value
+// (e.g. a key rotated in the secrets file) is reported as changed.
+func Diff(old, new *Config) []Change {
+	var out []Change
+	diffValue("", plain(old), plain(new), &out)
+	if old == nil || new == nil {
+		return out
+	}
+	a, b := make(map[string]Secret), make(map[string]Secret)
+	collectSecrets(reflect.ValueOf(old).Elem(), "", a)
+	collectSecrets(reflect.ValueOf(new).Elem(), "", b)
+	paths := make([]string, 0, len(b))
+	for p := range b {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if o, ok := a[p]; ok && o.Ref == b[p].Ref && o.value != b[p].value {
+			out = append(out, Change{Path: p, Kind: "changed"})
+		}
+	}
+	return out
+}
+
+// collectSecrets records every set Secret under v by YAML path.
+func collectSecrets(v reflect.Value, path string, out map[string]Secret) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			collectSecrets(v.Elem(), path, out)
+		}
+	case reflect.Struct:
+		if v.Type() == secretType {
+			if s := v.Interface().(Secret); s.IsSet() {
+				out[path] = s
+			}
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "-" {
+				continue
+			}
+			p := path
+			if !strings.Contains(","+opts+",", ",inline,") {
+				if name == "" {
+					name = strings.ToLower(f.Name)
+				}
+				p = joinPath(path, name)
+			}
+			collectSecrets(v.Field(i), p, out)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectSecrets(v.Index(i), fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	case reflect.Map:
+		if !containsSecret(v.Type().Elem()) {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			collectSecrets(iter.Value(), joinPath(path, fmt.Sprint(iter.Key().Interface())), out)
+		}
+	}
+}
+
+// plain converts cfg to generic YAML values (maps, slices, scalars); templates become their source.
+func plain(cfg *Config) any {
+	if cfg == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil
+	}
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+func diffValue(path string, a, b any, out *[]Change) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case a == nil:
+		*out = append(*out, Change{Path: path, Kind: "added"})
+		return
+	case b == nil:
+		*out = append(*out, Change{Path: path, Kind: "removed"})
+		return
+	}
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, dup := av[k]; !dup {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diffValue(joinPath(path, k), av[k], bv[k], out)
+		}
+		return
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(av), len(bv)); i++ {
+			var x, y any
+			if i < len(av) {
+				x = av[i]
+			}
+			if i < len(bv) {
+				y = bv[i]
+			}
+			diffValue(fmt.Sprintf("%s[%d]", path, i), x, y, out)
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*out = append(*out, Change{Path: path, Kind: "changed"})
+	}
+}
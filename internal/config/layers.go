@@ -0,0 +1,311 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar selects the environment overlay Load merges on top of the config file (e.g. "prod").
+const EnvVar = "OPENCLAW_ENV"
+
+// includeKey is the top-level key listing files to merge underneath the one that names them.
+const includeKey = "include"
+
+// source is the merged document a Config was decoded from, kept to report positions.
+type source struct {
+	doc *yaml.Node
+	// origin maps every node to the file it was read from.
+	origin map[*yaml.Node]string
+	// files are the files read, in merge order (includes first, overlay last).
+	files []string
+}
+
+// OverlayPath returns the overlay for env next to path: openclaw.yaml + "prod" -> openclaw.prod.yaml.
+func OverlayPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// Files returns the files c was loaded from in merge order, or nil for Default().
+func (c *Config) Files() []string {
+	if c.src == nil {
+		return nil
+	}
+	return slices.Clone(c.src.files)
+}
+
+// Sets reports whether the config files set the value at path (e.g. "memory.dir"); false for Default().
+func (c *Config) Sets(path string) bool {
+	return c.src != nil && lookup(c.src.doc, path) != nil
+}
+
+// loader reads a config file with its includes and overlay and merges them into one document.
+type loader struct {
+	// raw skips ${VAR} expansion and the per-file field checks (for printing files as written).
+	raw   bool
+	src   *source
+	errs  Errors
+	stack []string // absolute paths of the files being included, to detect cycles
+}
+
+func newLoader(raw bool) *loader {
+	return &loader{raw: raw, src: &source{origin: make(map[*yaml.Node]string)}}
+}
+
+// layers loads path, then the env overlay when env is set and the overlay exists, and returns the
+// merged top-level mapping (nil when everything is empty).
+func (l *loader) layers(path, env string) *yaml.Node {
+	root := l.file(path, nil, "")
+	if env == "" {
+		return root
+	}
+	overlay := OverlayPath(path, env)
+	if _, err := os.Stat(overlay); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			l.errs = append(l.errs, Issue{File: overlay, Msg: err.Error()})
+		}
+		return root
+	}
+	return mergeNodes(root, l.file(overlay, nil, ""), "")
+}
+
+// file parses one file and the files it includes; includes are merged first so the file itself
+// wins. from is the include entry that named path (nil for the top file) and includer its file.
+func (l *loader) file(path string, from *yaml.Node, includer string) *yaml.Node {
+	fail := func(msg string) *yaml.Node {
+		issue := Issue{File: path, Msg: msg}
+		if from != nil {
+			issue = Issue{File: includer, Line: from.Line, Column: from.Column, Path: includeKey, Msg: msg}
+		}
+		l.errs = append(l.errs, issue)
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fail(err.Error())
+	}
+	if i := slices.Index(l.stack, abs); i >= 0 {
+		return fail("include cycle: " + strings.Join(append(l.stack[i:], abs), " -> "))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fail(err.Error())
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		issue := positioned(strings.TrimPrefix(err.Error(), "yaml: "))
+		issue.File = path
+		l.errs = append(l.errs, issue)
+		return nil
+	}
+	if len(doc.Content) == 0 {
+		l.src.files = append(l.src.files, path)
+		return nil // empty file
+	}
+	root := doc.Content[0]
+	l.mark(root, path)
+	if root.Kind != yaml.MappingNode {
+		l.errs = append(l.errs, Issue{File: path, Line: root.Line, Column: root.Column, Msg: "top level must be a mapping"})
+		return nil
+	}
+
+	var errs Errors
+	if !l.raw {
+		interpolate(root, "", &errs)
+	}
+	includes := takeIncludes(root, &errs)
+	if !l.raw && len(errs) == 0 {
+		// Check each file on its own so positions point into the file that has the problem.
+		var scratch Config
+		checkFields(root, reflect.TypeOf(scratch), "", &errs)
+		errs = append(errs, decodeErrors(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, &scratch)...)
+	}
+	for i := range errs {
+		errs[i].File = path
+	}
+	l.errs = append(l.errs, errs...)
+
+	l.stack = append(l.stack, abs)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+	var merged *yaml.Node
+	for _, inc := range includes {
+		p := inc.Value
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(path), p)
+		}
+		merged = mergeNodes(merged, l.file(p, inc, path), "")
+	}
+	l.src.files = append(l.src.files, path)
+	return mergeNodes(merged, root, "")
+}
+
+// mark records path as the origin of n and everything under it.
+func (l *loader) mark(n *yaml.Node, path string) {
+	l.src.origin[n] = path
+	for _, c := range n.Content {
+		l.mark(c, path)
+	}
+}
+
+// takeIncludes removes the include key from root and returns its entries (a path or a list of paths).
+func takeIncludes(root *yaml.Node, errs *Errors) []*yaml.Node {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != includeKey {
+			continue
+		}
+		v := root.Content[i+1]
+		root.Content = slices.Delete(root.Content, i, i+2)
+		var entries []*yaml.Node
+		switch v.Kind {
+		case yaml.ScalarNode:
+			entries = []*yaml.Node{v}
+		case yaml.SequenceNode:
+			entries = v.Content
+		}
+		for _, e := range entries {
+			if e.Kind != yaml.ScalarNode || e.Value == "" {
+				*errs = append(*errs, Issue{Line: e.Line, Column: e.Column, Path: includeKey, Msg: "include entries must be file paths"})
+				return nil
+			}
+		}
+		if entries == nil && v.Tag != "!!null" {
+			*errs = append(*errs, Issue{Line: v.Line, Column: v.Column, Path: includeKey, Msg: "include must be a path or a list of paths"})
+		}
+		return entries
+	}
+	return nil
+}
+
+// mergeNodes merges src on top of dst and returns the result, reusing dst where it can:
+//   - mappings are merged key by key, recursively;
+//   - bindings are appended, so an overlay adds routes without repeating the base ones;
+//   - agents.list entries are merged by id, new ids are appended;
+//   - any other value (scalars, other lists) is replaced by src.
+func mergeNodes(dst, src *yaml.Node, path string) *yaml.Node {
+	switch {
+	case dst == nil:
+		return src
+	case src == nil:
+		return dst
+	}
+	if src.Kind == yaml.MappingNode && dst.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			k, v := src.Content[i], src.Content[i+1]
+			j := mappingIndex(dst, k.Value)
+			if j < 0 || k.Value == "<<" {
+				dst.Content = append(dst.Content, k, v)
+				continue
+			}
+			dst.Content[j+1] = mergeNodes(dst.Content[j+1], v, joinPath(path, k.Value))
+		}
+		return dst
+	}
+	if src.Kind == yaml.SequenceNode && dst.Kind == yaml.SequenceNode {
+		switch path {
+		case "bindings":
+			dst.Content = append(dst.Content, src.Content...)
+			return dst
+		case "agents.list":
+			for _, item := range src.Content {
+				id := agentKey(item)
+				j := slices.IndexFunc(dst.Content, func(n *yaml.Node) bool { return id != "" && agentKey(n) == id })
+				if j < 0 {
+					dst.Content = append(dst.Content, item)
+				} else {
+					dst.Content[j] = mergeNodes(dst.Content[j], item, path+"[]")
+				}
+			}
+			return dst
+		}
+	}
+	return src
+}
+
+// agentKey is the normalized id of an agents.list entry, or "" when it has none.
+func agentKey(n *yaml.Node) string {
+	if v := mappingValue(n, "id"); v != nil && v.Kind == yaml.ScalarNode {
+		return strings.ToLower(strings.TrimSpace(v.Value))
+	}
+	return ""
+}
+
+func mappingIndex(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// position returns the file, line and column of the value at path, or zeros when s is nil or the
+// path is not in the document (e.g. a default value).
+func (s *source) position(path string) (file string, line, column int) {
+	if s == nil {
+		return "", 0, 0
+	}
+	n := lookup(s.doc, path)
+	if n == nil {
+		return "", 0, 0
+	}
+	return s.origin[n], n.Line, n.Column
+}
+
+// issue builds an Issue for the value at path.
+func (s *source) issue(path, msg string) Issue {
+	file, line, col := s.position(path)
+	return Issue{File: file, Line: line, Column: col, Path: path, Msg: msg}
+}
+
+// LoadRaw merges path, its includes and the env overlay as written, without expanding ${VAR} or
+// resolving secrets. It returns the YAML, with literal Secret values redacted, and the files read.
+func LoadRaw(path, env string) ([]byte, []string, error) {
+	l := newLoader(true)
+	root := l.layers(path, env)
+	if len(l.errs) > 0 {
+		return nil, nil, l.errs
+	}
+	if root == nil {
+		return nil, l.src.files, nil
+	}
+	redactNode(root, reflect.TypeOf(Config{}))
+	out, err := yaml.Marshal(root)
+	return out, l.src.files, err
+}
+
+// redactNode replaces literal values of Secret fields under n with the redaction marker; refs
+// (env:, file:, secret:) and ${VAR} references are kept.
+func redactNode(n *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == secretType {
+		if n.Kind == yaml.ScalarNode && !strings.Contains(n.Value, "${") {
+			n.Value, n.Style = Secret{Ref: n.Value}.String(), 0
+		}
+		return
+	}
+	switch {
+	case t.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if ft, ok := fields[n.Content[i].Value]; ok {
+				redactNode(n.Content[i+1], ft)
+			}
+		}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && n.Kind == yaml.SequenceNode:
+		for _, c := range n.Content {
+			redactNode(c, t.Elem())
+		}
+	case t.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			redactNode(n.Content[i+1], t.Elem())
+		}
+	}
+}
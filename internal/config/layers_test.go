@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mapping parses doc and returns its top-level node (nil for an empty document).
+func mapping(t *testing.T, doc string) *yaml.Node {
+	t.Helper()
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &n); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Content) == 0 {
+		return nil
+	}
+	return n.Content[0]
+}
+
+func TestMergeNodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst, src string
+		want     string
+	}{
+		{name: "empty dst", src: "a: 1\n", want: "a: 1\n"},
+		{name: "empty src", dst: "a: 1\n", want: "a: 1\n"},
+		{name: "scalars replaced", dst: "a: 1\nb: x\n", src: "b: y\n", want: "a: 1\nb: y\n"},
+		{
+			name: "mappings merged",
+			dst:  "memory:\n  dir: /a\n  max_entries: 5\n",
+			src:  "memory:\n  max_entries: 9\n  enabled: true\n",
+			want: "memory:\n  dir: /a\n  max_entries: 9\n  enabled: true\n",
+		},
+		{name: "type change replaces", dst: "a:\n  b: 1\n", src: "a: off\n", want: "a: off\n"},
+		{name: "other lists replaced", dst: "include_roles: [a, b]\n", src: "include_roles: [c]\n", want: "include_roles: [c]\n"},
+		{
+			name: "bindings appended",
+			dst:  "bindings:\n  - agent_id: main\n",
+			src:  "bindings:\n  - agent_id: support\n",
+			want: "bindings:\n  - agent_id: main\n  - agent_id: support\n",
+		},
+		{
+			name: "agents.list merged by id",
+			dst:  "agents:\n  list:\n    - id: main\n      timezone: UTC\n      delegates: [a]\n    - id: support\n",
+			src:  "agents:\n  list:\n    - id: Main\n      delegates: [b]\n    - id: ops\n",
+			want: "agents:\n  list:\n    - id: Main\n      timezone: UTC\n      delegates: [b]\n    - id: support\n    - id: ops\n",
+		},
+		{
+			name: "agents.list entries without id appended",
+			dst:  "agents:\n  list:\n    - timezone: UTC\n",
+			src:  "agents:\n  list:\n    - timezone: UTC\n",
+			want: "agents:\n  list:\n    - timezone: UTC\n    - timezone: UTC\n",
+		},
+		{
+			name: "nested lists named like merged ones replaced",
+			dst:  "x:\n  bindings: [1]\n",
+			src:  "x:\n  bindings: [2]\n",
+			want: "x:\n  bindings: [2]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeNodes(mapping(t, tt.dst), mapping(t, tt.src), "")
+			var gotV, wantV any
+			if err := got.Decode(&gotV); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal([]byte(tt.want), &wantV); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotV, wantV) {
+				out, _ := yaml.Marshal(got)
+				t.Errorf("got:\n%swant:\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	shared := writeFile(t, dir, "shared.yaml", "memory:\n  max_entries: 1\n  dir: /shared\nrouting:\n  mode: first-match\n")
+	base := writeFile(t, dir, "conf.d/base.yaml",
+		"include: ../shared.yaml\nmemory:\n  max_entries: 2\nagents:\n  list:\n    - id: main\n      timezone: UTC\n"+
+			"bindings:\n  - agent_id: main\n    match:\n      channel: discord\n")
+	main := writeFile(t, dir, "openclaw.yaml",
+		"include:\n  - conf.d/base.yaml\nmemory:\n  max_entries: 3\nagents:\n  list:\n    - id: main\n      description: file\n"+
+			"bindings:\n  - agent_id: main\n    match:\n      channel: discord\n      guild_id: \"1\"\n")
+	overlay := writeFile(t, dir, "openclaw.prod.yaml",
+		"memory:\n  max_entries: 4\nagents:\n  list:\n    - id: ops\n"+
+			"bindings:\n  - agent_id: ops\n    match:\n      channel: discord\n      guild_id: \"2\"\n")
+
+	cfg, err := LoadEnv(main, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{shared, base, main}; !slices.Equal(cfg.Files(), want) {
+		t.Errorf("files = %v, want %v", cfg.Files(), want)
+	}
+	if cfg.Memory.MaxEntries != 3 || cfg.Memory.Dir != "/shared" || cfg.Routing.Mode != "first-match" {
+		t.Errorf("memory = %+v, routing = %+v; want the file over its includes", cfg.Memory, cfg.Routing)
+	}
+	if a := cfg.Agents.List; len(a) != 1 || a[0].Timezone != "UTC" || a[0].Description != "file" {
+		t.Errorf("agents = %+v, want main merged across files", a)
+	}
+	if len(cfg.Bindings) != 2 || cfg.Bindings[0].Match.GuildID != "" || cfg.Bindings[1].Match.GuildID != "1" {
+		t.Errorf("bindings = %+v, want the include's then the file's", cfg.Bindings)
+	}
+
+	cfg, err = LoadEnv(main, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{shared, base, main, overlay}; !slices.Equal(cfg.Files(), want) {
+		t.Errorf("files = %v, want %v", cfg.Files(), want)
+	}
+	if cfg.Memory.MaxEntries != 4 {
+		t.Errorf("max_entries = %d, want the overlay's 4", cfg.Memory.MaxEntries)
+	}
+	if a := cfg.Agents.List; len(a) != 2 || a[0].ID != "main" || a[1].ID != "ops" {
+		t.Errorf("agents = %+v, want main then ops", a)
+	}
+	if len(cfg.Bindings) != 3 || cfg.Bindings[2].AgentID != "ops" {
+		t.Errorf("bindings = %+v, want the overlay's appended", cfg.Bindings)
+	}
+
+	// A missing overlay is not an error.
+	if _, err := LoadEnv(main, "staging"); err != nil {
+		t.Errorf("missing overlay: %v", err)
+	}
+	if got, want := OverlayPath(main, "prod"), overlay; got != want {
+		t.Errorf("OverlayPath = %q, want %q", got, want)
+	}
+}
+
+func TestLoadIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.yaml", "include: sub/b.yaml\n")
+	b := writeFile(t, dir, "sub/b.yaml", "memory:\n  dir: /b\ninclude:\n  - ../a.yaml\n")
+	missing := writeFile(t, dir, "missing.yaml", "include: [nope.yaml]\n")
+	notPath := writeFile(t, dir, "notpath.yaml", "include:\n  - {a: 1}\n")
+	tests := []struct {
+		name string
+		path string
+		want Issue
+	}{
+		{
+			name: "cycle",
+			path: a,
+			want: Issue{File: b, Line: 4, Column: 5, Path: "include", Msg: "include cycle: " + a + " -> " + b + " -> " + a},
+		},
+		{
+			name: "missing",
+			path: missing,
+			want: Issue{File: missing, Line: 1, Column: 11, Path: "include", Msg: "open " + filepath.Join(dir, "nope.yaml") + ": no such file or directory"},
+		},
+		{
+			name: "not a path",
+			path: notPath,
+			want: Issue{File: notPath, Line: 2, Column: 5, Path: "include", Msg: "include entries must be file paths"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadEnv(tt.path, "")
+			var errs Errors
+			if !errors.As(err, &errs) || len(errs) != 1 || errs[0] != tt.want {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRawKeepsReferences(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.yaml", "channels:\n  discord:\n    token: hunter2\n")
+	main := writeFile(t, dir, "openclaw.yaml", "include: base.yaml\nmemory:\n  dir: ${OC_TEST_UNSET}\n")
+	out, files, err := LoadRaw(main, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || !strings.Contains(string(out), "${OC_TEST_UNSET}") || !strings.Contains(string(out), "[redacted]") || strings.Contains(string(out), "hunter2") {
+		t.Errorf("files = %v, out:\n%s", files, out)
+	}
+}
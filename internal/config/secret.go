@@ -0,0 +1,278 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// Secret is a credential such as a bot token or API key. In YAML it is a reference resolved at
+// load, or a literal value:
+//
+//	env:NAME        environment variable NAME
+//	file:/path      contents of a file (trailing newline trimmed), e.g. a mounted secret
+//	secret:KEY      KEY in the secrets file (goopenclaw.secrets)
+//
+// Secrets never marshal their value: references marshal as written, literals as "[redacted]".
+type Secret struct {
+	// Ref is the value as written in the config.
+	Ref   string
+	value string
+}
+
+// Value returns the resolved credential ("" when unset).
+func (s Secret) Value() string {
+	return s.value
+}
+
+// IsSet reports whether the config sets the secret.
+func (s Secret) IsSet() bool {
+	return s.Ref != ""
+}
+
+// OrEnv returns s when the config sets it, otherwise the secret read from environment variable
+// name (or the secrets file), as if the config had env:name. The value is registered for log
+// redaction. Plugins' default variables (MOONSHOT_API_KEY, ...) go through it.
+func (s Secret) OrEnv(name string) Secret {
+	if s.IsSet() || name == "" {
+		return s
+	}
+	v, _ := LookupEnv(name)
+	if v == "" {
+		return s
+	}
+	logging.AddSecrets(v)
+	return Secret{Ref: "env:" + name, value: v}
+}
+
+// String returns the reference, or "[redacted]" for literals.
+func (s Secret) String() string {
+	if s.Ref == "" || isSecretRef(s.Ref) {
+		return s.Ref
+	}
+	return "[redacted]"
+}
+
+// UnmarshalYAML records the reference; Load resolves it.
+func (s *Secret) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: secret must be a string (env:NAME, file:/path, secret:KEY or a literal)", n.Line)
+	}
+	s.Ref = strings.TrimSpace(n.Value)
+	return nil
+}
+
+// MarshalYAML writes the reference, never the value.
+func (s Secret) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
+
+func isSecretRef(ref string) bool {
+	for _, p := range []string{"env:", "file:", "secret:"} {
+		if strings.HasPrefix(ref, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretResolver resolves Secret references. secret: keys come from the secrets file loaded by
+// LoadSecrets, or from secretsPath, read on first use, when none was loaded.
+type secretResolver struct {
+	secretsPath string
+	secrets     map[string]string
+	secretsErr  error
+	read        bool
+}
+
+func (r *secretResolver) resolve(ref string) (string, error) {
+	kind, name, _ := strings.Cut(ref, ":")
+	if !isSecretRef(ref) {
+		return ref, nil
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("empty %s reference", kind)
+	}
+	switch kind {
+	case "env":
+		v, ok := LookupEnv(name)
+		if !ok || v == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case "file":
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("read secret file: %w", err)
+		}
+		v := strings.TrimRight(string(data), "\r\n")
+		if v == "" {
+			return "", fmt.Errorf("secret file %s is empty", name)
+		}
+		return v, nil
+	default: // secret:
+		if secretsLoaded() {
+			if v, _ := lookupSecret(name); v != "" {
+				return v, nil
+			}
+			return "", fmt.Errorf("%s is not set in the secrets file", name)
+		}
+		if !r.read {
+			r.read = true
+			r.secrets, r.secretsErr = ReadSecrets(r.secretsPath)
+		}
+		if r.secretsErr != nil {
+			if errors.Is(r.secretsErr, os.ErrNotExist) {
+				return "", fmt.Errorf("%s not found: secrets file %s does not exist", name, r.secretsPath)
+			}
+			return "", fmt.Errorf("read secrets file: %w", r.secretsErr)
+		}
+		v := r.secrets[name]
+		if v == "" {
+			return "", fmt.Errorf("%s is not set in %s", name, r.secretsPath)
+		}
+		return v, nil
+	}
+}
+
+var secretType = reflect.TypeOf(Secret{})
+
+// resolveSecrets resolves every Secret reachable from v, reporting failures by YAML path.
+func resolveSecrets(v reflect.Value, path string, r *secretResolver, src *source, errs *Errors) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			resolveSecrets(v.Elem(), path, r, src, errs)
+		}
+	case reflect.Struct:
+		if v.Type() == secretType {
+			s := v.Addr().Interface().(*Secret)
+			if s.Ref == "" {
+				return
+			}
+			val, err := r.resolve(s.Ref)
+			if err != nil {
+				*errs = append(*errs, src.issue(path, err.Error()))
+				return
+			}
+			s.value = val
+			logging.AddSecrets(val)
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "-" {
+				continue
+			}
+			p := path
+			if !strings.Contains(","+opts+",", ",inline,") {
+				if name == "" {
+					name = strings.ToLower(f.Name)
+				}
+				p = joinPath(path, name)
+			}
+			resolveSecrets(v.Field(i), p, r, src, errs)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			resolveSecrets(v.Index(i), fmt.Sprintf("%s[%d]", path, i), r, src, errs)
+		}
+	case reflect.Map:
+		if !containsSecret(v.Type().Elem()) {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values are not addressable: resolve a copy and store it back.
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(iter.Value())
+			resolveSecrets(e, joinPath(path, fmt.Sprint(iter.Key().Interface())), r, src, errs)
+			v.SetMapIndex(iter.Key(), e)
+		}
+	}
+}
+
+// containsSecret reports whether values of type t can hold a Secret.
+func containsSecret(t reflect.Type) bool {
+	return containsSecretSeen(t, map[reflect.Type]bool{})
+}
+
+func containsSecretSeen(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Array:
+		return containsSecretSeen(t.Elem(), seen)
+	case reflect.Struct:
+		if t == secretType {
+			return true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && containsSecretSeen(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var interpolateRe = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolate expands ${VAR} and ${VAR:-default} in the scalar values under n; $${ is a literal ${
+// and any other $ (including $$) is kept as written.
+// Keys are left alone. An unset variable without a default is reported at its position.
+func interpolate(n *yaml.Node, path string, errs *Errors) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			interpolate(c, path, errs)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			interpolate(c, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			interpolate(n.Content[i+1], joinPath(path, n.Content[i].Value), errs)
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(n.Value, "$") {
+			return
+		}
+		out := interpolateRe.ReplaceAllStringFunc(n.Value, func(m string) string {
+			if m == "$${" {
+				return "${"
+			}
+			sub := interpolateRe.FindStringSubmatch(m)
+			v, ok := LookupEnv(sub[1])
+			if sub[2] != "" && v == "" {
+				return sub[3] // like the shell, :- also replaces an empty value
+			}
+			if ok {
+				return v
+			}
+			*errs = append(*errs, Issue{Line: n.Line, Column: n.Column, Path: path, Msg: fmt.Sprintf("environment variable %s is not set (use ${%s:-default} for a fallback)", sub[1], sub[1])})
+			return ""
+		})
+		if out != n.Value {
+			n.Value = out
+			if n.Style == 0 || n.Style == yaml.FlowStyle {
+				n.Tag = "" // let plain scalars resolve again, so "${PORT}" can fill an int
+			}
+		}
+	}
+}
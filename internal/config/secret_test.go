@@ -0,0 +1,178 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("OC_TEST_NAME", "bot")
+	t.Setenv("OC_TEST_EMPTY", "")
+	tests := []struct {
+		name  string
+		value string
+		want  string
+		errs  []Issue
+	}{
+		{name: "plain", value: "hello", want: "hello"},
+		{name: "var", value: "${OC_TEST_NAME}", want: "bot"},
+		{name: "embedded", value: "x-${OC_TEST_NAME}-y", want: "x-bot-y"},
+		{name: "default unused", value: "${OC_TEST_NAME:-other}", want: "bot"},
+		{name: "default", value: "${OC_TEST_UNSET:-other}", want: "other"},
+		{name: "empty default", value: "${OC_TEST_UNSET:-}", want: ""},
+		{name: "default replaces empty", value: "${OC_TEST_EMPTY:-other}", want: "other"},
+		{name: "empty without default", value: "${OC_TEST_EMPTY}", want: ""},
+		{name: "dollar kept", value: "pa$$word $5 $OC_TEST_NAME", want: "pa$$word $5 $OC_TEST_NAME"},
+		{name: "escaped", value: "$${OC_TEST_NAME}", want: "${OC_TEST_NAME}"},
+		{name: "escaped then var", value: "$${X} ${OC_TEST_NAME}", want: "${X} bot"},
+		{name: "not a name", value: "${1X}", want: "${1X}"},
+		{
+			name:  "missing",
+			value: "a${OC_TEST_UNSET}b",
+			want:  "ab",
+			errs:  []Issue{{Line: 2, Column: 9, Path: "agent.name", Msg: "environment variable OC_TEST_UNSET is not set (use ${OC_TEST_UNSET:-default} for a fallback)"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte("agent:\n  name: "+tt.value+"\n"), &doc); err != nil {
+				t.Fatal(err)
+			}
+			var errs Errors
+			interpolate(&doc, "", &errs)
+			if got := doc.Content[0].Content[1].Content[1].Value; got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual([]Issue(errs), tt.errs) {
+				t.Errorf("issues = %v, want %v", errs, tt.errs)
+			}
+		})
+	}
+}
+
+func TestInterpolateKeysAndTypes(t *testing.T) {
+	t.Setenv("OC_TEST_MAX", "7")
+	path := writeFile(t, t.TempDir(), "openclaw.yaml", "memory:\n  max_entries: ${OC_TEST_MAX}\n")
+	cfg, err := LoadEnv(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Memory.MaxEntries != 7 {
+		t.Errorf("max_entries = %d, want 7", cfg.Memory.MaxEntries)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("${OC_TEST_MAX}: '${OC_TEST_MAX}'\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	interpolate(&doc, "", new(Errors))
+	m := doc.Content[0]
+	if m.Content[0].Value != "${OC_TEST_MAX}" {
+		t.Errorf("key = %q, want it left alone", m.Content[0].Value)
+	}
+	if m.Content[1].Value != "7" || m.Content[1].Tag != "!!str" {
+		t.Errorf("quoted value = %q (%s), want \"7\" kept a string", m.Content[1].Value, m.Content[1].Tag)
+	}
+}
+
+func TestSecretResolve(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OC_TEST_TOKEN", "env-token")
+	t.Setenv("OC_TEST_EMPTY", "")
+	tokenFile := writeFile(t, dir, "token", "file-token\n")
+	emptyFile := writeFile(t, dir, "empty", "\n")
+	secretsFile := writeFile(t, dir, "goopenclaw.secrets", "OC_SECRET=from-secrets\n")
+	tests := []struct {
+		name        string
+		ref         string
+		secretsPath string
+		want        string
+		err         string
+	}{
+		{name: "literal", ref: "abc", want: "abc"},
+		{name: "env", ref: "env:OC_TEST_TOKEN", want: "env-token"},
+		{name: "env spaces", ref: "env: OC_TEST_TOKEN ", want: "env-token"},
+		{name: "env unset", ref: "env:OC_TEST_UNSET", err: "environment variable OC_TEST_UNSET is not set"},
+		{name: "env empty", ref: "env:OC_TEST_EMPTY", err: "environment variable OC_TEST_EMPTY is not set"},
+		{name: "env no name", ref: "env:", err: "empty env reference"},
+		{name: "file", ref: "file:" + tokenFile, want: "file-token"},
+		{name: "file missing", ref: "file:" + filepath.Join(dir, "nope"), err: "read secret file: "},
+		{name: "file empty", ref: "file:" + emptyFile, err: "secret file " + emptyFile + " is empty"},
+		{name: "secret", ref: "secret:OC_SECRET", secretsPath: secretsFile, want: "from-secrets"},
+		{name: "secret missing key", ref: "secret:OC_OTHER", secretsPath: secretsFile, err: "OC_OTHER is not set in " + secretsFile},
+		{
+			name:        "secret missing file",
+			ref:         "secret:OC_SECRET",
+			secretsPath: filepath.Join(dir, "nope.secrets"),
+			err:         "OC_SECRET not found: secrets file " + filepath.Join(dir, "nope.secrets") + " does not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &secretResolver{secretsPath: tt.secretsPath}
+			got, err := r.resolve(tt.ref)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("got %q, %v; want error %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadResolvesSecrets(t *testing.T) {
+	t.Setenv("OC_TEST_TOKEN", "env-token")
+	path := writeFile(t, t.TempDir(), "openclaw.yaml",
+		"channels:\n  discord:\n    token: env:OC_TEST_TOKEN\n    accounts:\n      work:\n        token: env:OC_TEST_UNSET\n")
+	_, err := LoadEnv(path, "")
+	want := path + ": line 6, column 16: channels.discord.accounts.work.token: environment variable OC_TEST_UNSET is not set"
+	if err == nil || err.Error() != want {
+		t.Fatalf("err = %v, want %q", err, want)
+	}
+
+	path = writeFile(t, t.TempDir(), "openclaw.yaml", "channels:\n  discord:\n    token: env:OC_TEST_TOKEN\n")
+	cfg, err := LoadEnv(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Channels.Discord.Token.Value(); got != "env-token" {
+		t.Errorf("token = %q, want env-token", got)
+	}
+}
+
+func TestSecretString(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"", ""},
+		{"env:TOKEN", "env:TOKEN"},
+		{"file:/run/secrets/token", "file:/run/secrets/token"},
+		{"secret:TOKEN", "secret:TOKEN"},
+		{"hunter2", "[redacted]"},
+	}
+	for _, tt := range tests {
+		s := Secret{Ref: tt.ref, value: "v"}
+		if got := s.String(); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+		out, err := yaml.Marshal(struct {
+			Token Secret `yaml:"token"`
+		}{s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(out), "hunter2") || strings.Contains(string(out), ": v\n") {
+			t.Errorf("marshal %q leaked the value: %s", tt.ref, out)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/openclaw/openclaw-go/internal/logging"
+)
+
+// SecretsFileName is the name of the local secrets file.
+const SecretsFileName = "goopenclaw.secrets"
+
+// SecretsSearchPaths returns the places the secrets file is looked for, in order:
+// OPENCLAW_SECRETS (when set, the only candidate), ./goopenclaw.secrets, ~/.openclaw/goopenclaw.secrets.
+func SecretsSearchPaths() []string {
+	if p := os.Getenv("OPENCLAW_SECRETS"); p != "" {
+		return []string{p}
+	}
+	var out []string
+	if cwd, _ := os.Getwd(); cwd != "" {
+		out = append(out, filepath.Join(cwd, SecretsFileName))
+	}
+	if home, _ := os.UserHomeDir(); home != "" {
+		out = append(out, filepath.Join(home, ".openclaw", SecretsFileName))
+	}
+	if len(out) == 0 {
+		out = append(out, SecretsFileName)
+	}
+	return out
+}
+
+// ResolveSecretsPath returns the first existing file of SecretsSearchPaths, or the first candidate
+// when none exists.
+func ResolveSecretsPath() string {
+	paths := SecretsSearchPaths()
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return paths[0]
+}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   map[string]string
+)
+
+// LoadSecrets reads the secrets file at path (see ReadSecrets) and keeps its values in memory for
+// Getenv, ${VAR}, env: and secret: references. The process environment is not modified, so child
+// processes never inherit the values; every value is registered for log redaction.
+func LoadSecrets(path string) error {
+	values, err := ReadSecrets(path)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		logging.AddSecrets(v)
+	}
+	secretsMu.Lock()
+	secrets = values
+	secretsMu.Unlock()
+	return nil
+}
+
+// Getenv returns the environment variable key, falling back to the loaded secrets file.
+func Getenv(key string) string {
+	v, _ := LookupEnv(key)
+	return v
+}
+
+// LookupEnv is os.LookupEnv with the loaded secrets file as a fallback.
+func LookupEnv(key string) (string, bool) {
+	if v, ok := os.LookupEnv(key); ok {
+		return v, true
+	}
+	return lookupSecret(key)
+}
+
+func lookupSecret(key string) (string, bool) {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	v, ok := secrets[key]
+	return v, ok
+}
+
+// secretsLoaded reports whether LoadSecrets has run.
+func secretsLoaded() bool {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	return secrets != nil
+}
+
+var secretKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// ReadSecrets parses a dotenv-style file: KEY=VALUE lines with optional "export " prefixes, # comments,
+// 'single-quoted' literal values and "double-quoted" values with \n, \t, \", \\ escapes that may span
+// lines. Files readable by the group or other users are refused.
+func ReadSecrets(path string) (map[string]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm()&0o044 != 0 {
+		return nil, fmt.Errorf("secrets file %s is readable by group or other users (mode %#o); run: chmod 600 %s", path, fi.Mode().Perm(), path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parseDotenv(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func parseDotenv(src string) (map[string]string, error) {
+	out := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, val, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !secretKeyRe.MatchString(key) {
+			return nil, fmt.Errorf("line %d: want KEY=VALUE", lineNo)
+		}
+		val = strings.TrimLeft(val, " \t")
+		switch {
+		case strings.HasPrefix(val, "'"):
+			end := strings.Index(val[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", lineNo)
+			}
+			if rest := strings.TrimSpace(val[end+2:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected text after quoted value", lineNo)
+			}
+			val = val[1 : end+1]
+		case strings.HasPrefix(val, `"`):
+			// Double-quoted values may continue on the following lines.
+			body := val[1:]
+			for !hasClosingQuote(body) {
+				if i+1 >= len(lines) {
+					return nil, fmt.Errorf("line %d: unterminated double quote", lineNo)
+				}
+				i++
+				body += "\n" + lines[i]
+			}
+			end := closingQuote(body)
+			if rest := strings.TrimSpace(body[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected text after quoted value", lineNo)
+			}
+			val = unescape(body[:end])
+		default:
+			if j := strings.Index(val, " #"); j >= 0 {
+				val = val[:j]
+			}
+			val = strings.TrimSpace(val)
+		}
+		out[key] = val
+	}
+	return out, nil
+}
+
+func hasClosingQuote(s string) bool {
+	return closingQuote(s) >= 0
+}
+
+// closingQuote returns the index of the first unescaped '"' in s, or -1.
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\', '$':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
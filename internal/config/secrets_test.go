@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]string
+		err  string
+	}{
+		{name: "empty", src: "", want: map[string]string{}},
+		{name: "plain", src: "A=1\nB = two words \n", want: map[string]string{"A": "1", "B": "two words"}},
+		{name: "comments and blanks", src: "# c\n\n  # indented\nA=1 # note\nB=x#y\n", want: map[string]string{"A": "1", "B": "x#y"}},
+		{name: "export", src: "export A=1\nexport  B=2\n", want: map[string]string{"A": "1", "B": "2"}},
+		{name: "empty value", src: "A=\nB=''\nC=\"\"\n", want: map[string]string{"A": "", "B": "", "C": ""}},
+		{name: "dotted key", src: "discord.token-2=x\n", want: map[string]string{"discord.token-2": "x"}},
+		{name: "crlf", src: "A=1\r\nB=2\r\n", want: map[string]string{"A": "1", "B": "2"}},
+		{name: "value with =", src: "A=x=y\n", want: map[string]string{"A": "x=y"}},
+		{name: "single quoted literal", src: `A='a\nb $X #c' # note`, want: map[string]string{"A": `a\nb $X #c`}},
+		{name: "double quoted escapes", src: `A="a\nb\tc\r\"q\" \\ \$X \z"`, want: map[string]string{"A": "a\nb\tc\r\"q\" \\ $X \\z"}},
+		{name: "double quoted hash", src: `A="x # y" # note`, want: map[string]string{"A": "x # y"}},
+		{name: "multiline", src: "A=\"line1\nline2\"\nB=3\n", want: map[string]string{"A": "line1\nline2", "B": "3"}},
+		{name: "later wins", src: "A=1\nA=2\n", want: map[string]string{"A": "2"}},
+		{name: "no equals", src: "A=1\nJUSTKEY\n", err: "line 2: want KEY=VALUE"},
+		{name: "bad key", src: "1A=x\n", err: "line 1: want KEY=VALUE"},
+		{name: "unterminated single", src: "A='x\n", err: "line 1: unterminated single quote"},
+		{name: "unterminated double", src: "B=1\nA=\"x\ny\n", err: "line 2: unterminated double quote"},
+		{name: "text after single", src: "A='x' y\n", err: "line 1: unexpected text after quoted value"},
+		{name: "text after double", src: "A=\"x\"y\n", err: "line 1: unexpected text after quoted value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotenv(tt.src)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got %v, %v; want error %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSecretsPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not checked on Windows")
+	}
+	tests := []struct {
+		mode os.FileMode
+		ok   bool
+	}{
+		{0o600, true},
+		{0o400, true},
+		{0o700, true},
+		{0o640, false},
+		{0o604, false},
+		{0o644, false},
+	}
+	for _, tt := range tests {
+		path := writeFile(t, t.TempDir(), SecretsFileName, "A=1\n")
+		if err := os.Chmod(path, tt.mode); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadSecrets(path)
+		if tt.ok {
+			if err != nil || got["A"] != "1" {
+				t.Errorf("mode %#o: got %v, %v; want A=1", tt.mode, got, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), "is readable by group or other users") || !strings.Contains(err.Error(), "chmod 600") {
+			t.Errorf("mode %#o: got %v, %v; want a permission error", tt.mode, got, err)
+		}
+	}
+}
+
+func TestLoadSecretsDoesNotSetenv(t *testing.T) {
+	t.Cleanup(func() {
+		secretsMu.Lock()
+		secrets = nil
+		secretsMu.Unlock()
+	})
+	t.Setenv("OC_TEST_SHADOWED", "from-env")
+	path := writeFile(t, t.TempDir(), SecretsFileName, "OC_TEST_SECRET=from-file\nOC_TEST_SHADOWED=from-file\n")
+	if err := LoadSecrets(path); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := os.LookupEnv("OC_TEST_SECRET"); ok {
+		t.Errorf("os.LookupEnv = %q, want the secret kept out of the process environment", v)
+	}
+	if v, ok := LookupEnv("OC_TEST_SECRET"); !ok || v != "from-file" {
+		t.Errorf("LookupEnv = %q, %v; want from-file", v, ok)
+	}
+	if v := Getenv("OC_TEST_SECRET"); v != "from-file" {
+		t.Errorf("Getenv = %q, want from-file", v)
+	}
+	if v := Getenv("OC_TEST_SHADOWED"); v != "from-env" {
+		t.Errorf("Getenv = %q, want the environment to take precedence", v)
+	}
+	r := &secretResolver{}
+	if v, err := r.resolve("secret:OC_TEST_SECRET"); err != nil || v != "from-file" {
+		t.Errorf("secret: = %q, %v; want from-file", v, err)
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Issue is one problem in a config file. Line and Column are 0 when the position is unknown.
+type Issue struct {
+	// File is the file the problem is in; "" when unknown or not tied to a file.
+	File   string
+	Line   int
+	Column int
+	// Path locates the value, e.g. "agents.list[1].id".
+	Path string
+	Msg  string
+}
+
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.File != "" {
+		b.WriteString(i.File + ": ")
+	}
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d", i.Line)
+		if i.Column > 0 {
+			fmt.Fprintf(&b, ", column %d", i.Column)
+		}
+		b.WriteString(": ")
+	}
+	if i.Path != "" {
+		b.WriteString(i.Path + ": ")
+	}
+	b.WriteString(i.Msg)
+	return b.String()
+}
+
+// Errors lists the problems found in a config; Load and Validate return it.
+type Errors []Issue
+
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, issue := range e {
+		lines[i] = issue.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// decodeErrors decodes doc into cfg and returns yaml.v3's type errors as Issues.
+func decodeErrors(doc *yaml.Node, cfg *Config) Errors {
+	err := doc.Decode(cfg)
+	if err == nil {
+		return nil
+	}
+	var terr *yaml.TypeError
+	if !errors.As(err, &terr) {
+		return Errors{positioned(err.Error())}
+	}
+	errs := make(Errors, len(terr.Errors))
+	for i, msg := range terr.Errors {
+		errs[i] = positioned(msg)
+	}
+	return errs
+}
+
+var lineRe = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// positioned turns a yaml.v3 message ("line 3: cannot unmarshal ...") into an Issue.
+func positioned(msg string) Issue {
+	if m := lineRe.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return Issue{Line: line, Msg: m[2]}
+	}
+	return Issue{Msg: msg}
+}
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// checkFields reports mapping keys under n that t has no field for. Type mismatches are left to Decode.
+func checkFields(n *yaml.Node, t reflect.Type, path string, errs *Errors) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Value == "<<" { // merge key
+				checkFields(v, t, path, errs)
+				continue
+			}
+			ft, ok := fields[k.Value]
+			if !ok {
+				msg := fmt.Sprintf("unknown field %q", k.Value)
+				if s := suggest(k.Value, fields); s != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", s)
+				}
+				*errs = append(*errs, Issue{Line: k.Line, Column: k.Column, Path: path, Msg: msg})
+				continue
+			}
+			checkFields(v, ft, joinPath(path, k.Value), errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, c := range n.Content {
+			checkFields(c, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			checkFields(n.Content[i+1], t.Elem(), joinPath(path, n.Content[i].Value), errs)
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of struct t to field types, flattening ",inline" structs.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	out := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+opts+",", ",inline,") && f.Type.Kind() == reflect.Struct {
+			for k, v := range yamlFields(f.Type) {
+				out[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		out[name] = f.Type
+	}
+	return out
+}
+
+// suggest returns the field closest to key when it is likely a typo (case, dashes or a small edit).
+func suggest(key string, fields map[string]reflect.Type) string {
+	norm := strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	best, bestDist := "", 3
+	for name := range fields {
+		if name == norm {
+			return name
+		}
+		if d := editDistance(norm, name); d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// lookup returns the node of the value at path ("bindings[0].agent_id") under root, or nil.
+func lookup(root *yaml.Node, path string) *yaml.Node {
+	if root == nil {
+		return nil
+	}
+	n := root
+	for _, seg := range strings.Split(path, ".") {
+		key, idx, _ := strings.Cut(seg, "[")
+		if key != "" {
+			if n = mappingValue(n, key); n == nil {
+				return nil
+			}
+		}
+		for idx != "" {
+			var rest string
+			idx, rest, _ = strings.Cut(idx, "]")
+			i, err := strconv.Atoi(idx)
+			if n.Kind == yaml.AliasNode && n.Alias != nil {
+				n = n.Alias
+			}
+			if err != nil || n.Kind != yaml.SequenceNode || i < 0 || i >= len(n.Content) {
+				return nil
+			}
+			n = n.Content[i]
+			idx = strings.TrimPrefix(rest, "[")
+		}
+	}
+	return n
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
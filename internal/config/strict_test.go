@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes content to name under dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// loadIssues loads doc from a file and validates it, returning the issues found.
+func loadIssues(t *testing.T, doc string) (string, Errors) {
+	t.Helper()
+	path := writeFile(t, t.TempDir(), "openclaw.yaml", doc)
+	cfg, err := LoadEnv(path, "")
+	if err == nil {
+		err = cfg.Validate(ValidateOptions{LLMProviders: []string{"kimi"}})
+	}
+	if err == nil {
+		return path, nil
+	}
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %T %v, want Errors", err, err)
+	}
+	return path, errs
+}
+
+func TestLoadAndValidateIssues(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []Issue // File is filled in with the test file
+	}{
+		{
+			name: "valid",
+			doc:  "agents:\n  list:\n    - id: main\nbindings:\n  - agent_id: main\n    match:\n      channel: discord\n",
+		},
+		{
+			name: "unknown field",
+			doc:  "agents:\n  list:\n    - agent_Id: main\n",
+			want: []Issue{{Line: 3, Column: 7, Path: "agents.list[0]", Msg: `unknown field "agent_Id"`}},
+		},
+		{
+			name: "typo with suggestion",
+			doc:  "session:\n  dm_scop: main\n  Identity-Links: {}\n",
+			want: []Issue{
+				{Line: 2, Column: 3, Path: "session", Msg: `unknown field "dm_scop" (did you mean "dm_scope"?)`},
+				{Line: 3, Column: 3, Path: "session", Msg: `unknown field "Identity-Links" (did you mean "identity_links"?)`},
+			},
+		},
+		{
+			name: "wrong type",
+			doc:  "memory:\n  max_entries: many\n",
+			want: []Issue{{Line: 2, Msg: "cannot unmarshal !!str `many` into int"}},
+		},
+		{
+			name: "bad enum",
+			doc:  "session:\n  dm_scope: per-user\nrouting:\n  mode: fastest\n",
+			want: []Issue{
+				{Line: 4, Column: 9, Path: "routing.mode", Msg: `invalid routing mode "fastest" (want tiered or first-match)`},
+				{Line: 2, Column: 13, Path: "session.dm_scope", Msg: `invalid dm_scope "per-user" (want main, per-peer, per-channel-peer, per-account-channel-peer)`},
+			},
+		},
+		{
+			name: "bad binding",
+			doc: "agents:\n  list:\n    - id: main\nbindings:\n" +
+				"  - agent_id: support\n    match:\n      channel: discord\n" +
+				"  - agent_id: main\n    match: {}\n" +
+				"  - agent_id: main\n    match:\n      channel: discord\n      peer: {kind: room, id: \"1\"}\n      guild_id: \"re:[\"\n",
+			want: []Issue{
+				{Line: 5, Column: 15, Path: "bindings[0].agent_id", Msg: `unknown agent "support" (not in agents.list)`},
+				{Line: 9, Column: 12, Path: "bindings[1].match", Msg: "match.channel is required"},
+				{Line: 13, Column: 20, Path: "bindings[2].match.peer.kind", Msg: `invalid peer kind "room" (want dm, group, channel, thread)`},
+				{Line: 14, Column: 17, Path: "bindings[2].match.guild_id", Msg: "invalid regular expression: error parsing regexp: missing closing ]: `[`"},
+			},
+		},
+		{
+			name: "duplicate agent",
+			doc:  "agents:\n  list:\n    - id: main\n    - id: Main\n",
+			want: []Issue{{Line: 4, Column: 11, Path: "agents.list[1].id", Msg: `duplicate agent id "Main" (also agents.list[0].id)`}},
+		},
+		{
+			name: "unknown llm provider",
+			doc:  "agents:\n  defaults:\n    llm_provider: gpt\n",
+			want: []Issue{{Line: 3, Column: 19, Path: "agents.defaults.llm_provider", Msg: `unknown llm provider "gpt" (available: kimi)`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, got := loadIssues(t, tt.doc)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d issues, want %d:\n%v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				want.File = path
+				if got[i] != want {
+					t.Errorf("issue %d:\ngot  %#v\nwant %#v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestIssueString(t *testing.T) {
+	tests := []struct {
+		issue Issue
+		want  string
+	}{
+		{Issue{File: "a.yaml", Line: 3, Column: 7, Path: "agents.list[0]", Msg: "bad"}, "a.yaml: line 3, column 7: agents.list[0]: bad"},
+		{Issue{File: "a.yaml", Line: 3, Msg: "bad"}, "a.yaml: line 3: bad"},
+		{Issue{Path: "routing.mode", Msg: "bad"}, "routing.mode: bad"},
+	}
+	for _, tt := range tests {
+		if got := tt.issue.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLoadMissingFileUsesDefaults(t *testing.T) {
+	cfg, err := LoadEnv(filepath.Join(t.TempDir(), "missing.yaml"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Agents.Defaults.LLMProvider != Default().Agents.Defaults.LLMProvider || cfg.Files() != nil {
+		t.Errorf("got %+v, want Default()", cfg)
+	}
+}
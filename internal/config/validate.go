@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ValidateOptions lists the plugins the running binary provides.
+type ValidateOptions struct {
+	// LLMProviders are the registered LLM plugin ids; nil skips the agents.defaults.llm_provider check.
+	LLMProviders []string
+	// EmbeddingsProviders are the registered embeddings plugin ids; nil skips the memory.embeddings check.
+	EmbeddingsProviders []string
+}
+
+var (
+	agentIDRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	dailyAtRe = regexp.MustCompile(`^([01]?[0-9]|2[0-3]):[0-5][0-9]$`)
+
+	dmScopes        = []string{"main", "per-peer", "per-channel-peer", "per-account-channel-peer"}
+	peerKinds       = []string{"dm", "group", "channel", "thread"}
+	chatTypes       = []string{"direct", "group", "channel", "thread"}
+	replyToModes    = []string{"first", "all", "off"}
+	routingModes    = []string{"tiered", "first-match"}
+	archiveDuration = []int{60, 1440, 4320, 10080}
+)
+
+// Validate checks what decoding cannot: agent ids, binding targets, enumerated values and plugin ids.
+// It returns Errors, positioned in the file when c came from Load, or nil.
+func (c *Config) Validate(opts ValidateOptions) error {
+	v := &validator{src: c.src}
+
+	agents := make(map[string]string) // normalized id -> path
+	for i, a := range c.Agents.List {
+		p := fmt.Sprintf("agents.list[%d].id", i)
+		id := strings.ToLower(strings.TrimSpace(a.ID))
+		switch {
+		case id == "":
+			v.add(fmt.Sprintf("agents.list[%d]", i), "agent id is required")
+		case !agentIDRe.MatchString(id):
+			v.add(p, fmt.Sprintf("invalid agent id %q (use a-z, 0-9, _ or -, up to 64 characters)", a.ID))
+		case agents[id] != "":
+			v.add(p, fmt.Sprintf("duplicate agent id %q (also %s)", a.ID, agents[id]))
+		default:
+			agents[id] = p
+		}
+		v.timezone(fmt.Sprintf("agents.list[%d].timezone", i), a.Timezone)
+		if a.Envelope != nil {
+			v.timezone(fmt.Sprintf("agents.list[%d].envelope.timezone", i), a.Envelope.Timezone)
+		}
+	}
+	if len(c.Agents.List) == 0 {
+		agents["main"] = "default agent"
+	}
+	for i, a := range c.Agents.List {
+		self := strings.ToLower(strings.TrimSpace(a.ID))
+		for j, d := range a.Delegates {
+			p := fmt.Sprintf("agents.list[%d].delegates[%d]", i, j)
+			switch id := strings.ToLower(strings.TrimSpace(d)); {
+			case id == "*":
+			case id == self:
+				v.add(p, "an agent cannot delegate to itself")
+			case agents[id] == "":
+				v.add(p, fmt.Sprintf("unknown agent %q (not in agents.list)", d))
+			}
+		}
+	}
+	if dl := c.Agents.Defaults.Delegation; dl.MaxDepth < 0 || dl.TimeoutSeconds < 0 {
+		v.add("agents.defaults.delegation", "max_depth and timeout_seconds must not be negative")
+	}
+
+	for i, b := range c.Bindings {
+		p := fmt.Sprintf("bindings[%d]", i)
+		switch id := strings.ToLower(strings.TrimSpace(b.AgentID)); {
+		case id == "":
+			v.add(p, "agent_id is required")
+		case agents[id] == "":
+			v.add(p+".agent_id", fmt.Sprintf("unknown agent %q (not in agents.list)", b.AgentID))
+		}
+		if strings.TrimSpace(b.Match.Channel) == "" {
+			v.add(p+".match", "match.channel is required")
+		}
+		m := b.Match
+		if m.Peer != nil {
+			if !slices.Contains(peerKinds, m.Peer.Kind) {
+				v.add(p+".match.peer.kind", fmt.Sprintf("invalid peer kind %q (want %s)", m.Peer.Kind, strings.Join(peerKinds, ", ")))
+			}
+			if strings.TrimSpace(m.Peer.ID) == "" && len(m.Peer.IDs) == 0 {
+				v.add(p+".match.peer", "peer needs id or ids")
+			}
+			v.pattern(p+".match.peer.id", m.Peer.ID)
+			v.patterns(p+".match.peer.ids", m.Peer.IDs)
+		}
+		v.pattern(p+".match.guild_id", m.GuildID)
+		v.pattern(p+".match.team_id", m.TeamID)
+		v.pattern(p+".match.parent", m.Parent)
+		v.patterns(p+".match.channel_ids", m.ChannelIDs)
+		v.patterns(p+".match.roles", m.Roles)
+	}
+
+	if m := c.Routing.Mode; m != "" && !slices.Contains(routingModes, m) {
+		v.add("routing.mode", fmt.Sprintf("invalid routing mode %q (want %s)", m, strings.Join(routingModes, " or ")))
+	}
+
+	d := c.Agents.Defaults
+	if pid := d.LLMProvider; pid != "" && opts.LLMProviders != nil && !slices.Contains(opts.LLMProviders, pid) {
+		v.add("agents.defaults.llm_provider", fmt.Sprintf("unknown llm provider %q (available: %s)", pid, strings.Join(opts.LLMProviders, ", ")))
+	}
+	v.timezone("agents.defaults.timezone", d.Timezone)
+	v.timezone("agents.defaults.envelope.timezone", d.Envelope.Timezone)
+
+	s := c.Session
+	if s.DMScope != "" && !slices.Contains(dmScopes, s.DMScope) {
+		v.add("session.dm_scope", fmt.Sprintf("invalid dm_scope %q (want %s)", s.DMScope, strings.Join(dmScopes, ", ")))
+	}
+	v.reset("session.reset", s.Reset)
+	for _, t := range sortedKeys(s.ResetByChatType) {
+		p := "session.reset_by_chat_type." + t
+		if !slices.Contains(chatTypes, t) {
+			v.add(p, fmt.Sprintf("unknown chat type %q (want %s)", t, strings.Join(chatTypes, ", ")))
+		}
+		v.reset(p, s.ResetByChatType[t])
+	}
+
+	if e := c.Memory.Embeddings; e.Provider != "" && e.BaseURL == "" && c.Providers[e.Provider].BaseURL == "" &&
+		opts.EmbeddingsProviders != nil && !slices.Contains(opts.EmbeddingsProviders, e.Provider) {
+		v.add("memory.embeddings.provider", fmt.Sprintf("unknown embeddings provider %q (available: %s; or set base_url)",
+			e.Provider, strings.Join(opts.EmbeddingsProviders, ", ")))
+	}
+
+	if opts.LLMProviders != nil && opts.EmbeddingsProviders != nil {
+		for _, id := range sortedKeys(c.Providers) {
+			if !slices.Contains(opts.LLMProviders, id) && !slices.Contains(opts.EmbeddingsProviders, id) &&
+				id != c.Memory.Embeddings.Provider {
+				v.add("providers."+id, fmt.Sprintf("unknown provider %q", id))
+			}
+		}
+	}
+
+	for _, id := range sortedKeys(c.Channels.Discord.Accounts) {
+		if !c.Channels.Discord.Accounts[id].Token.IsSet() {
+			v.add("channels.discord.accounts."+id, "token is required")
+		}
+	}
+	for _, g := range sortedKeys(c.Channels.Discord.Guilds) {
+		guild := c.Channels.Discord.Guilds[g]
+		p := "channels.discord.guilds." + g
+		v.discordChannel(p, guild.DiscordChannelConfig)
+		for _, ch := range sortedKeys(guild.Channels) {
+			v.discordChannel(p+".channels."+ch, guild.Channels[ch])
+		}
+	}
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+type validator struct {
+	src  *source
+	errs Errors
+}
+
+func (v *validator) add(path, msg string) {
+	v.errs = append(v.errs, v.src.issue(path, msg))
+}
+
+// pattern checks a binding id pattern: a glob or "re:" regular expression must compile.
+func (v *validator) pattern(field, p string) {
+	p = strings.TrimSpace(p)
+	if expr, ok := strings.CutPrefix(p, "re:"); ok {
+		if _, err := regexp.Compile(expr); err != nil {
+			v.add(field, fmt.Sprintf("invalid regular expression: %v", err))
+		}
+	} else if _, err := path.Match(p, ""); err != nil {
+		v.add(field, fmt.Sprintf("invalid glob %q", p))
+	}
+}
+
+func (v *validator) patterns(path string, ps []string) {
+	for i, p := range ps {
+		if strings.TrimSpace(p) == "" {
+			v.add(fmt.Sprintf("%s[%d]", path, i), "empty id")
+			continue
+		}
+		v.pattern(fmt.Sprintf("%s[%d]", path, i), p)
+	}
+}
+
+func (v *validator) timezone(path, tz string) {
+	if tz == "" {
+		return
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		v.add(path, fmt.Sprintf("unknown timezone %q", tz))
+	}
+}
+
+func (v *validator) reset(path string, r SessionResetConfig) {
+	if r.DailyAt != "" && r.DailyAt != "off" && !dailyAtRe.MatchString(r.DailyAt) {
+		v.add(path+".daily_at", fmt.Sprintf("invalid daily_at %q (want HH:MM or off)", r.DailyAt))
+	}
+	v.timezone(path+".timezone", r.Timezone)
+}
+
+func (v *validator) discordChannel(path string, c DiscordChannelConfig) {
+	if m := strings.ToLower(strings.TrimSpace(c.ReplyToMode)); m != "" && !slices.Contains(replyToModes, m) {
+		v.add(path+".reply_to_mode", fmt.Sprintf("invalid reply_to_mode %q (want %s)", c.ReplyToMode, strings.Join(replyToModes, ", ")))
+	}
+	if c.AutoArchiveMinutes != 0 && !slices.Contains(archiveDuration, c.AutoArchiveMinutes) {
+		v.add(path+".auto_archive_minutes", fmt.Sprintf("invalid auto_archive_minutes %d (want 60, 1440, 4320 or 10080)", c.AutoArchiveMinutes))
+	}
+	if c.HistoryLimit < 0 || c.HistoryLimit > 50 {
+		v.add(path+".history_limit", fmt.Sprintf("history_limit %d out of range (0-50)", c.HistoryLimit))
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
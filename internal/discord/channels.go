@@ -0,0 +1,150 @@
+package discord
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/openclaw/openclaw-go/internal/routing"
+)
+
+// DefaultChannelCacheTTL is how long resolved channel metadata is reused.
+const DefaultChannelCacheTTL = 10 * time.Minute
+
+// DefaultChannelMissTTL is how long a failed lookup is remembered, so channels the bot cannot
+// read do not cost a REST call per message.
+const DefaultChannelMissTTL = time.Minute
+
+// ChannelInfo is the channel metadata preflight needs to classify a message.
+type ChannelInfo struct {
+	ID       string
+	Name     string
+	GuildID  string
+	ParentID string // parent text/forum channel for threads
+	OwnerID  string // creator of a thread
+	Type     discordgo.ChannelType
+}
+
+// IsThread reports whether the channel is a guild thread.
+func (c *ChannelInfo) IsThread() bool {
+	switch c.Type {
+	case discordgo.ChannelTypeGuildPublicThread, discordgo.ChannelTypeGuildPrivateThread, discordgo.ChannelTypeGuildNewsThread:
+		return true
+	}
+	return false
+}
+
+// ChannelCache resolves channel metadata from the gateway state first and the REST API second,
+// caching results for TTL. It is safe for concurrent use.
+type ChannelCache struct {
+	Session *discordgo.Session
+	TTL     time.Duration
+	// MissTTL applies to lookups that failed (default DefaultChannelMissTTL).
+	MissTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedChannel
+}
+
+type cachedChannel struct {
+	info    *ChannelInfo
+	fetched time.Time
+}
+
+// Resolve returns metadata for channelID, or nil if it cannot be resolved.
+func (c *ChannelCache) Resolve(channelID string) *ChannelInfo {
+	if c == nil || channelID == "" {
+		return nil
+	}
+	c.mu.Lock()
+	if e, ok := c.entries[channelID]; ok && time.Since(e.fetched) < c.ttl(e.info) {
+		c.mu.Unlock()
+		return e.info
+	}
+	c.mu.Unlock()
+
+	ch := c.fetch(channelID)
+	if ch == nil {
+		c.store(channelID, nil)
+		return nil
+	}
+	info := &ChannelInfo{ID: ch.ID, Name: ch.Name, GuildID: ch.GuildID, ParentID: ch.ParentID, OwnerID: ch.OwnerID, Type: ch.Type}
+	c.Put(info)
+	return info
+}
+
+// Put caches info, e.g. for a thread the bot just created.
+func (c *ChannelCache) Put(info *ChannelInfo) {
+	if c == nil || info == nil {
+		return
+	}
+	c.store(info.ID, info)
+}
+
+// store caches info (nil for a failed lookup) for channelID.
+func (c *ChannelCache) store(channelID string, info *ChannelInfo) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedChannel)
+	}
+	c.entries[channelID] = cachedChannel{info: info, fetched: time.Now()}
+	c.mu.Unlock()
+}
+
+// ttl returns how long an entry stays valid: MissTTL for failed lookups, TTL otherwise.
+func (c *ChannelCache) ttl(info *ChannelInfo) time.Duration {
+	if info == nil {
+		if c.MissTTL > 0 {
+			return c.MissTTL
+		}
+		return DefaultChannelMissTTL
+	}
+	if c.TTL > 0 {
+		return c.TTL
+	}
+	return DefaultChannelCacheTTL
+}
+
+// Forget drops a cached channel (e.g. after ChannelUpdate / ThreadUpdate events).
+func (c *ChannelCache) Forget(channelID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	delete(c.entries, channelID)
+	c.mu.Unlock()
+}
+
+func (c *ChannelCache) fetch(channelID string) *discordgo.Channel {
+	if c.Session == nil {
+		return nil
+	}
+	if c.Session.State != nil {
+		if ch, err := c.Session.State.Channel(channelID); err == nil && ch != nil {
+			return ch
+		}
+	}
+	ch, err := c.Session.Channel(channelID)
+	if err != nil {
+		return nil
+	}
+	return ch
+}
+
+// conversationKind classifies a message into a chat type and its routing peers.
+// chatType is "direct", "group", "channel" or "thread"; parent is set for threads only.
+func conversationKind(info *ChannelInfo, guildID, channelID, authorID string) (chatType string, peer, parent *routing.RoutePeer) {
+	switch {
+	case info != nil && info.Type == discordgo.ChannelTypeGroupDM:
+		return "group", &routing.RoutePeer{Kind: routing.PeerGroup, ID: channelID}, nil
+	case info != nil && info.IsThread():
+		var p *routing.RoutePeer
+		if info.ParentID != "" {
+			p = &routing.RoutePeer{Kind: routing.PeerChannel, ID: info.ParentID}
+		}
+		return "thread", &routing.RoutePeer{Kind: routing.PeerThread, ID: channelID}, p
+	case guildID == "" && (info == nil || info.Type == discordgo.ChannelTypeDM):
+		return "direct", &routing.RoutePeer{Kind: routing.PeerDM, ID: authorID}, nil
+	}
+	return "channel", &routing.RoutePeer{Kind: routing.PeerChannel, ID: channelID}, nil
+}
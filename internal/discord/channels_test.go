@@ -0,0 +1,58 @@
+package discord
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestChannelCacheRemembersMisses(t *testing.T) {
+	s, err := discordgo.New("Bot test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.MaxRestRetries = 0
+	calls := 0
+	s.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Missing Access", "code": 50001}`)),
+			Request:    r,
+		}, nil
+	})}
+	c := &ChannelCache{Session: s}
+
+	for i := 0; i < 3; i++ {
+		if info := c.Resolve("c1"); info != nil {
+			t.Fatalf("Resolve = %+v, want nil", info)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("%d REST calls, want 1", calls)
+	}
+
+	// The channel becomes visible once the miss expires.
+	if err := s.State.ChannelAdd(&discordgo.Channel{ID: "c1", Name: "general", Type: discordgo.ChannelTypeGroupDM}); err != nil {
+		t.Fatal(err)
+	}
+	if info := c.Resolve("c1"); info != nil {
+		t.Errorf("Resolve before the miss expired = %+v, want nil", info)
+	}
+	c.MissTTL = time.Nanosecond
+	if info := c.Resolve("c1"); info == nil || info.Name != "general" {
+		t.Errorf("Resolve after the miss expired = %+v", info)
+	}
+	if calls != 1 {
+		t.Errorf("%d REST calls, want 1", calls)
+	}
+}
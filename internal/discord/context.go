@@ -0,0 +1,73 @@
+package discord
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+)
+
+// replyContext returns the message msg replies to, using the copy Discord embeds in the event and
+// fetching it when that is missing. Returns nil for non-replies or when it cannot be loaded.
+func replyContext(s *discordgo.Session, msg *discordgo.Message) *inbound.QuotedMessage {
+	ref := msg.ReferencedMessage
+	if ref == nil && msg.MessageReference != nil && msg.MessageReference.MessageID != "" && s != nil {
+		channelID := msg.MessageReference.ChannelID
+		if channelID == "" {
+			channelID = msg.ChannelID
+		}
+		var err error
+		if ref, err = s.ChannelMessage(channelID, msg.MessageReference.MessageID); err != nil {
+			slog.Debug("discord: fetch replied-to message", "err", err, "msgId", msg.MessageReference.MessageID)
+			return nil
+		}
+	}
+	if ref == nil {
+		return nil
+	}
+	q := quoteMessage(ref)
+	return &q
+}
+
+// channelHistory returns up to limit messages posted in channelID before beforeID, oldest first.
+// The bot's own messages are left out; they are already in the session history.
+func channelHistory(s *discordgo.Session, channelID, beforeID, botUserID string, limit int) []inbound.QuotedMessage {
+	if s == nil || limit <= 0 {
+		return nil
+	}
+	msgs, err := s.ChannelMessages(channelID, min(limit, MaxHistoryLimit), beforeID, "", "")
+	if err != nil {
+		slog.Debug("discord: fetch channel history", "err", err, "channel", channelID)
+		return nil
+	}
+	var out []inbound.QuotedMessage
+	for _, m := range msgs {
+		if m.Author == nil || (botUserID != "" && m.Author.ID == botUserID) {
+			continue
+		}
+		if q := quoteMessage(m); q.Body != "" {
+			out = append(out, q)
+		}
+	}
+	slices.Reverse(out) // Discord returns newest first
+	return out
+}
+
+func quoteMessage(m *discordgo.Message) inbound.QuotedMessage {
+	q := inbound.QuotedMessage{ID: m.ID, Body: strings.TrimSpace(m.Content), Timestamp: m.Timestamp.UnixMilli()}
+	if m.Author != nil {
+		q.SenderId = m.Author.ID
+		q.SenderName = formatUserTag(m.Author)
+		if m.Member != nil && m.Member.Nick != "" {
+			q.SenderName = m.Member.Nick + " (" + q.SenderName + ")"
+		}
+	}
+	for _, a := range m.Attachments {
+		if a != nil {
+			q.Body = strings.TrimSpace(q.Body + " [attachment: " + a.Filename + "]")
+		}
+	}
+	return q
+}
@@ -3,23 +3,31 @@ package discord
 import (
 	"context"
 	"log/slog"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/openclaw/openclaw-go/internal/config"
 	"github.com/openclaw/openclaw-go/internal/dispatch"
 	"github.com/openclaw/openclaw-go/internal/gateway"
 	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/routing"
+	"github.com/openclaw/openclaw-go/internal/session"
 )
 
 // MessageHandler handles incoming Discord messages (debounce + preflight + process).
 type MessageHandler struct {
-	Cfg             *config.Config
-	DiscordCfg      *DiscordConfig
-	AccountID       string
-	BotUserID       string
-	DMEnabled       bool
-	GroupDMEnabled  bool
-	GuildEntries    map[string]GuildEntry
+	// Config returns the active config; it is read once per message so reloads apply to the next one.
+	Config         func() *config.Config
+	DiscordCfg     *DiscordConfig
+	AccountID      string
+	BotUserID      string
+	DMEnabled      bool
+	GroupDMEnabled bool
+	GuildEntries   map[string]GuildEntry
+	// Channels resolves channel types (DM / group DM / thread); nil falls back to guild-less = DM.
+	Channels *ChannelCache
+	// Sessions is used to archive thread sessions when their thread is archived; may be nil.
+	Sessions *session.Store
 	// DispatchInbound is called to process the message (from gateway runtime).
 	// If nil, messages are not dispatched.
 	DispatchInbound func(ctx context.Context, msgCtx *inbound.MsgContext, d gateway.Dispatcher) error
@@ -28,9 +36,10 @@ type MessageHandler struct {
 // Handle is called for each MessageCreate event.
 func (h *MessageHandler) Handle(s *discordgo.Session, m *discordgo.MessageCreate) {
 	ctx := context.Background()
+	cfg := h.Config()
 
-	pre := Preflight(PreflightParams{
-		Cfg:            h.Cfg,
+	params := PreflightParams{
+		Cfg:            cfg,
 		DiscordCfg:     h.DiscordCfg,
 		AccountID:      h.AccountID,
 		BotUserID:      h.BotUserID,
@@ -38,21 +47,112 @@ func (h *MessageHandler) Handle(s *discordgo.Session, m *discordgo.MessageCreate
 		DMEnabled:      h.DMEnabled,
 		GroupDMEnabled: h.GroupDMEnabled,
 		GuildEntries:   h.GuildEntries,
-	})
+		Channel:        h.Channels.Resolve(m.ChannelID),
+	}
+	pre := Preflight(params)
 	if pre == nil {
 		return
 	}
+	policy := ResolveChannelPolicy(cfg, pre.GuildID, pre.ChannelID, pre.ThreadParentID)
+	if pre.IsGuildMessage && policy.RequireMention && !pre.WasMentioned && !h.ownsThread(params.Channel) {
+		slog.Debug("discord: drop message without mention", "channel", pre.ChannelID)
+		return
+	}
 	if h.DispatchInbound == nil {
 		slog.Debug("discord: no DispatchInbound, skip")
 		return
 	}
+	replyMode := policy.ReplyMode
+	statusChannel := ""
+	if policy.AutoThread && pre.ChatType == "channel" && pre.WasMentioned {
+		if threaded := h.startThread(s, params, pre, policy); threaded != nil {
+			statusChannel = pre.ChannelID
+			pre = threaded
+			// The thread hangs off the triggering message already; replies can't reference across channels.
+			replyMode = dispatch.ReplyOff
+		}
+	}
 
-	disp := &dispatch.DiscordDispatcher{Session: s, ChannelID: pre.ChannelID}
+	disp := &dispatch.DiscordDispatcher{
+		Session:         s,
+		ChannelID:       pre.ChannelID,
+		ReplyMode:       replyMode,
+		MentionOnReply:  policy.MentionOnReply,
+		Typing:          true,
+		StatusReactions: true,
+		StatusChannelID: statusChannel,
+	}
+	if cfg != nil {
+		dc := cfg.Channels.Discord
+		disp.ChunkLimit = dc.TextChunkLimit
+		disp.AttachAboveChunks = dc.AttachAboveChunks
+		disp.MaxUploadBytes = dc.MaxUploadBytes
+		disp.Typing = dc.Typing == nil || *dc.Typing
+		disp.StatusReactions = dc.StatusReactions == nil || *dc.StatusReactions
+	}
 	err := ProcessMessage(ctx, pre, ProcessOpts{
-		DispatchInbound: h.DispatchInbound,
-		Dispatcher:      disp,
+		DispatchInbound:  h.DispatchInbound,
+		Dispatcher:       disp,
+		Session:          s,
+		BotUserID:        h.BotUserID,
+		ReplyContext:     policy.ReplyContext,
+		HistoryLimit:     policy.HistoryLimit,
+		HistoryChannelID: statusChannel,
 	})
 	if err != nil {
 		slog.Error("discord process failed", "err", err, "msgId", pre.Message.ID)
 	}
 }
+
+// HandleThreadUpdate archives the sessions of a thread once Discord archives (or deletes) it.
+func (h *MessageHandler) HandleThreadUpdate(threadID string, archived bool) {
+	if !archived || h.Sessions == nil {
+		return
+	}
+	for _, key := range h.Sessions.Keys() {
+		if routing.IsThreadSessionKey(key, threadID) {
+			h.Sessions.Reset(key, session.ResetThreadArchived, false)
+		}
+	}
+}
+
+// startThread creates a thread from the triggering message and re-runs preflight inside it, so the
+// conversation (and its session key) lives in the thread. Returns nil if the thread cannot be created.
+func (h *MessageHandler) startThread(s *discordgo.Session, params PreflightParams, pre *PreflightContext, policy ChannelPolicy) *PreflightContext {
+	th, err := s.MessageThreadStartComplex(pre.ChannelID, pre.Message.ID, &discordgo.ThreadStart{
+		Name:                threadName(pre),
+		AutoArchiveDuration: policy.AutoArchiveMinutes,
+	})
+	if err != nil {
+		slog.Warn("discord: create thread, replying inline", "err", err, "channel", pre.ChannelID)
+		return nil
+	}
+	info := &ChannelInfo{ID: th.ID, Name: th.Name, GuildID: pre.GuildID, ParentID: pre.ChannelID, OwnerID: h.BotUserID, Type: th.Type}
+	h.Channels.Put(info)
+
+	msg := *pre.Message
+	msg.ChannelID = th.ID
+	params.Data = &discordgo.MessageCreate{Message: &msg}
+	params.Channel = info
+	return Preflight(params)
+}
+
+// ownsThread reports whether the channel is a thread created by the bot; follow-ups there need no mention.
+func (h *MessageHandler) ownsThread(info *ChannelInfo) bool {
+	return info != nil && info.IsThread() && h.BotUserID != "" && info.OwnerID == h.BotUserID
+}
+
+// threadName derives a thread title (max 100 chars) from the first line of the message.
+func threadName(pre *PreflightContext) string {
+	name := strings.TrimSpace(pre.MessageText)
+	if i := strings.IndexByte(name, '\n'); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+	if name == "" {
+		name = "Chat with " + pre.Author.Username
+	}
+	if r := []rune(name); len(r) > 90 {
+		name = string(r[:90]) + "…"
+	}
+	return name
+}
@@ -0,0 +1,191 @@
+package discord
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/openclaw/openclaw-go/internal/commands"
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/dispatch"
+	"github.com/openclaw/openclaw-go/internal/gateway"
+	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/routing"
+)
+
+// NativeCommands converts the router's commands into Discord application command definitions.
+func NativeCommands(r *commands.Router, cfg *config.Config) []*discordgo.ApplicationCommand {
+	var out []*discordgo.ApplicationCommand
+	for _, c := range r.Commands() {
+		if !commands.CommandEnabled(cfg, c.Name) {
+			continue
+		}
+		ac := &discordgo.ApplicationCommand{
+			Name:        c.Name,
+			Description: truncateDescription(c.Description),
+		}
+		if c.ArgName != "" {
+			ac.Options = []*discordgo.ApplicationCommandOption{{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        c.ArgName,
+				Description: truncateDescription(c.ArgDescription),
+			}}
+		}
+		out = append(out, ac)
+	}
+	return out
+}
+
+// RegisterNativeCommands overwrites the bot's global application commands with the router's commands.
+func RegisterNativeCommands(s *discordgo.Session, appID string, r *commands.Router, cfg *config.Config) error {
+	_, err := s.ApplicationCommandBulkOverwrite(appID, "", NativeCommands(r, cfg))
+	return err
+}
+
+// InteractionHandler handles application command interactions by turning them into
+// command messages and dispatching them through the same pipeline as text messages.
+// Commands are refused in conversations that message preflight would drop.
+type InteractionHandler struct {
+	// Config returns the active config, read once per interaction.
+	Config         func() *config.Config
+	DiscordCfg     *DiscordConfig
+	AccountID      string
+	BotUserID      string
+	DMEnabled      bool
+	GroupDMEnabled bool
+	GuildEntries   map[string]GuildEntry
+	Channels       *ChannelCache
+	// DispatchInbound is called to process the command (from gateway runtime).
+	DispatchInbound func(ctx context.Context, msgCtx *inbound.MsgContext, d gateway.Dispatcher) error
+}
+
+// Handle is called for each InteractionCreate event.
+func (h *InteractionHandler) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand || h.DispatchInbound == nil {
+		return
+	}
+	data := i.ApplicationCommandData()
+	user := i.User
+	var roles []string
+	if i.Member != nil {
+		user = i.Member.User
+		roles = i.Member.Roles
+	}
+	if user == nil {
+		return
+	}
+
+	// Acknowledge before anything that may call the REST API: Discord drops interactions
+	// that are not answered within 3 seconds.
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		slog.Error("discord: defer interaction", "err", err, "command", data.Name)
+		return
+	}
+	ctx := context.Background()
+	disp := &dispatch.InteractionDispatcher{Session: s, Interaction: i.Interaction, Ephemeral: true}
+
+	cfg := h.Config()
+	chatType, peer, parentPeer := conversationKind(h.Channels.Resolve(i.ChannelID), i.GuildID, i.ChannelID, user.ID)
+	params := PreflightParams{
+		DiscordCfg:     h.DiscordCfg,
+		BotUserID:      h.BotUserID,
+		DMEnabled:      h.DMEnabled,
+		GroupDMEnabled: h.GroupDMEnabled,
+		GuildEntries:   h.GuildEntries,
+	}
+	if reason := refuseReason(params, user, i.GuildID, chatType); reason != "" {
+		slog.Debug("discord: refuse command", "reason", reason, "command", data.Name, "channel", i.ChannelID)
+		if err := disp.SendFinal(ctx, "", "", "Commands are not available in this conversation."); err != nil {
+			slog.Error("discord: answer interaction", "err", err, "command", data.Name)
+		}
+		return
+	}
+
+	body := "/" + data.Name
+	for _, opt := range data.Options {
+		if opt.Type == discordgo.ApplicationCommandOptionString {
+			body += " " + opt.StringValue()
+		}
+	}
+
+	from, replyTarget := "#"+i.ChannelID, "channel:"+i.ChannelID
+	if chatType == "direct" {
+		from, replyTarget = formatUserTag(user), "user:"+user.ID
+	}
+	routeInput := routing.ResolveAgentRouteInput{
+		Cfg:           cfg,
+		Channel:       "discord",
+		AccountID:     h.AccountID,
+		Peer:          peer,
+		ParentPeer:    parentPeer,
+		GuildID:       i.GuildID,
+		MemberRoleIDs: roles,
+	}
+	route := routing.ResolveAgentRoute(routeInput)
+	routeInput.Cfg = nil
+
+	msgCtx := &inbound.MsgContext{
+		Body:               body,
+		RawBody:            body,
+		CommandBody:        body,
+		From:               from,
+		To:                 i.ChannelID,
+		SessionKey:         route.SessionKey,
+		AgentID:            route.AgentID,
+		AccountID:          h.AccountID,
+		GuildID:            i.GuildID,
+		ChatType:           chatType,
+		ConversationLabel:  from,
+		SenderName:         formatUserTag(user),
+		SenderId:           user.ID,
+		SenderUsername:     user.Username,
+		SenderRoles:        roles,
+		Provider:           "discord",
+		Surface:            "discord",
+		WasMentioned:       true,
+		MessageSid:         i.ID,
+		Timestamp:          interactionTime(i.ID).UnixMilli(),
+		CommandAuthorized:  commands.SenderAllowed(cfg, "discord", user.ID, roles),
+		NativeCommand:      true,
+		OriginatingChannel: "discord",
+		OriginatingTo:      replyTarget,
+		ReplyChannelID:     i.ChannelID,
+		RouteInput:         &routeInput,
+	}
+
+	if err := h.DispatchInbound(ctx, msgCtx, disp); err != nil {
+		slog.Error("discord interaction failed", "err", err, "command", data.Name)
+	}
+	if !disp.Sent {
+		if err := s.InteractionResponseDelete(i.Interaction); err != nil {
+			slog.Debug("discord: delete deferred response", "err", err)
+		}
+	}
+}
+
+// interactionTime returns when the interaction was created, from its snowflake ID.
+func interactionTime(id string) time.Time {
+	t, err := discordgo.SnowflakeTimestamp(id)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
+
+// truncateDescription keeps descriptions within Discord's 1-100 character limit.
+func truncateDescription(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "-"
+	}
+	if r := []rune(s); len(r) > 100 {
+		return string(r[:97]) + "..."
+	}
+	return s
+}
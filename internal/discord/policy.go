@@ -0,0 +1,78 @@
+package discord
+
+import (
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+	"github.com/openclaw/openclaw-go/internal/dispatch"
+)
+
+// DefaultAutoArchiveMinutes is used for auto-created threads when no duration is configured.
+const DefaultAutoArchiveMinutes = 1440
+
+// MaxHistoryLimit is the most channel messages fetched for context (one REST page).
+const MaxHistoryLimit = 50
+
+// ChannelPolicy is the effective channels.discord configuration for one conversation.
+type ChannelPolicy struct {
+	RequireMention     bool
+	AutoThread         bool
+	AutoArchiveMinutes int
+	ReplyMode          dispatch.ReplyMode
+	MentionOnReply     bool
+	ReplyContext       bool
+	HistoryLimit       int
+}
+
+// ResolveChannelPolicy merges guild and channel settings for a message. Threads pass their parent
+// channel as parentID and inherit its entry unless the thread has its own. DMs get the defaults,
+// with plain (non-reply) messages.
+func ResolveChannelPolicy(cfg *config.Config, guildID, channelID, parentID string) ChannelPolicy {
+	p := ChannelPolicy{AutoArchiveMinutes: DefaultAutoArchiveMinutes, ReplyMode: dispatch.ReplyFirst, ReplyContext: true}
+	if guildID == "" {
+		p.ReplyMode = dispatch.ReplyOff
+	}
+	if cfg == nil || guildID == "" {
+		return p
+	}
+	guilds := cfg.Channels.Discord.Guilds
+	g, ok := guilds[guildID]
+	if !ok {
+		g = guilds["*"]
+	}
+	p.apply(g.DiscordChannelConfig)
+	if parentID != "" {
+		if c, ok := g.Channels[parentID]; ok {
+			p.apply(c)
+		}
+	}
+	if c, ok := g.Channels[channelID]; ok {
+		p.apply(c)
+	}
+	return p
+}
+
+func (p *ChannelPolicy) apply(c config.DiscordChannelConfig) {
+	if c.RequireMention != nil {
+		p.RequireMention = *c.RequireMention
+	}
+	if c.AutoThread != nil {
+		p.AutoThread = *c.AutoThread
+	}
+	if c.AutoArchiveMinutes > 0 {
+		p.AutoArchiveMinutes = c.AutoArchiveMinutes
+	}
+	switch m := dispatch.ReplyMode(strings.ToLower(strings.TrimSpace(c.ReplyToMode))); m {
+	case dispatch.ReplyFirst, dispatch.ReplyAll, dispatch.ReplyOff:
+		p.ReplyMode = m
+	}
+	if c.MentionOnReply != nil {
+		p.MentionOnReply = *c.MentionOnReply
+	}
+	if c.ReplyContext != nil {
+		p.ReplyContext = *c.ReplyContext
+	}
+	if c.HistoryLimit > 0 {
+		p.HistoryLimit = min(c.HistoryLimit, MaxHistoryLimit)
+	}
+}
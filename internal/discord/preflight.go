@@ -2,8 +2,10 @@ package discord
 
 import (
 	"log/slog"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/openclaw/openclaw-go/internal/commands"
 	"github.com/openclaw/openclaw-go/internal/config"
 	"github.com/openclaw/openclaw-go/internal/routing"
 )
@@ -17,7 +19,11 @@ type PreflightParams struct {
 	Data            *discordgo.MessageCreate
 	DMEnabled       bool
 	GroupDMEnabled  bool
+	// GuildEntries, when set, limits the bot to guilds with Allow set ("*" covers unlisted guilds).
 	GuildEntries    map[string]GuildEntry
+	// Channel is the resolved metadata of the message's channel (see ChannelCache). When nil,
+	// guild-less messages are treated as direct messages.
+	Channel         *ChannelInfo
 }
 
 // DiscordConfig (simplified).
@@ -44,6 +50,11 @@ type PreflightContext struct {
 	IsGuildMessage  bool
 	IsDirectMessage bool
 	IsGroupDM       bool
+	IsThread        bool
+	// ThreadParentID is the parent channel of a thread message.
+	ThreadParentID  string
+	// ChatType is "direct", "group", "channel" or "thread".
+	ChatType        string
 	BaseText        string
 	MessageText     string
 	WasMentioned    bool
@@ -51,6 +62,9 @@ type PreflightContext struct {
 	ChannelID       string
 	ChannelName     string
 	Route           routing.ResolvedAgentRoute
+	// RouteInput is what Route was resolved from (without Cfg).
+	RouteInput      routing.ResolveAgentRouteInput
+	SenderRoles     []string
 	CommandAuthorized bool
 }
 
@@ -65,55 +79,41 @@ func Preflight(p PreflightParams) *PreflightContext {
 		return nil
 	}
 
-	if author.Bot {
-		if p.BotUserID != "" && author.ID == p.BotUserID {
-			return nil
-		}
-		if p.DiscordCfg == nil || !p.DiscordCfg.AllowBots {
-			slog.Debug("discord: drop bot message")
-			return nil
-		}
-	}
-
 	isGuild := p.Data.GuildID != ""
-	var isDM, isGroupDM bool
-	if p.Data.GuildID == "" {
-		if len(p.Data.Mentions) > 0 || msg.Type == discordgo.MessageTypeDefault {
-			isDM = true
+	chatType, peer, parentPeer := conversationKind(p.Channel, p.Data.GuildID, msg.ChannelID, author.ID)
+	isDM := chatType == "direct"
+	isGroupDM := chatType == "group"
+	isThread := chatType == "thread"
+
+	if reason := refuseReason(p, author, p.Data.GuildID, chatType); reason != "" {
+		if reason != "own message" {
+			slog.Debug("discord: drop message", "reason", reason, "channel", msg.ChannelID)
 		}
-	}
-
-	if isGroupDM && !p.GroupDMEnabled {
-		slog.Debug("discord: drop group dm (disabled)")
-		return nil
-	}
-	if isDM && !p.DMEnabled {
-		slog.Debug("discord: drop dm (disabled)")
 		return nil
 	}
 
 	baseText := msg.Content
-	messageText := baseText
+	messageText := stripBotMention(baseText, p.BotUserID)
 
-	peerKind := routing.PeerChannel
-	peerID := msg.ChannelID
-	if isDM {
-		peerKind = routing.PeerDM
-		peerID = author.ID
-	} else if isGroupDM {
-		peerKind = routing.PeerGroup
+	var roles []string
+	if msg.Member != nil {
+		roles = msg.Member.Roles
 	}
 
-	route := routing.ResolveAgentRoute(routing.ResolveAgentRouteInput{
-		Cfg:       p.Cfg,
-		Channel:   "discord",
-		AccountID: p.AccountID,
-		Peer:      &routing.RoutePeer{Kind: peerKind, ID: peerID},
-		GuildID:   p.Data.GuildID,
-	})
+	routeInput := routing.ResolveAgentRouteInput{
+		Cfg:           p.Cfg,
+		Channel:       "discord",
+		AccountID:     p.AccountID,
+		Peer:          peer,
+		ParentPeer:    parentPeer,
+		GuildID:       p.Data.GuildID,
+		MemberRoleIDs: roles,
+	}
+	route := routing.ResolveAgentRoute(routeInput)
+	routeInput.Cfg = nil
 
 	wasMentioned := false
-	if isGuild && p.BotUserID != "" {
+	if (isGuild || isGroupDM) && p.BotUserID != "" {
 		for _, u := range msg.Mentions {
 			if u.ID == p.BotUserID {
 				wasMentioned = true
@@ -126,8 +126,14 @@ func Preflight(p PreflightParams) *PreflightContext {
 	}
 
 	channelName := msg.ChannelID
-	if msg.ChannelID != "" && p.Data.GuildID != "" {
-		channelName = msg.ChannelID
+	threadParentID := ""
+	if p.Channel != nil {
+		if p.Channel.Name != "" {
+			channelName = p.Channel.Name
+		}
+		if isThread {
+			threadParentID = p.Channel.ParentID
+		}
 	}
 
 	return &PreflightContext{
@@ -140,6 +146,9 @@ func Preflight(p PreflightParams) *PreflightContext {
 		IsGuildMessage:   isGuild,
 		IsDirectMessage:  isDM,
 		IsGroupDM:        isGroupDM,
+		IsThread:         isThread,
+		ThreadParentID:   threadParentID,
+		ChatType:         chatType,
 		BaseText:         baseText,
 		MessageText:      messageText,
 		WasMentioned:     wasMentioned,
@@ -147,6 +156,52 @@ func Preflight(p PreflightParams) *PreflightContext {
 		ChannelID:        msg.ChannelID,
 		ChannelName:      channelName,
 		Route:            route,
-		CommandAuthorized: true,
+		RouteInput:       routeInput,
+		SenderRoles:      roles,
+		CommandAuthorized: commands.SenderAllowed(p.Cfg, "discord", author.ID, roles),
+	}
+}
+
+// refuseReason applies the sender and conversation filters shared by messages and slash commands:
+// the bot's own and other bots' messages, disabled DMs and group DMs, and guilds not allowed by
+// GuildEntries. It returns why the conversation is refused, or "" if it is allowed.
+func refuseReason(p PreflightParams, author *discordgo.User, guildID, chatType string) string {
+	if author.Bot {
+		if p.BotUserID != "" && author.ID == p.BotUserID {
+			return "own message"
+		}
+		if p.DiscordCfg == nil || !p.DiscordCfg.AllowBots {
+			return "bot"
+		}
+	}
+	switch chatType {
+	case "group":
+		if !p.GroupDMEnabled {
+			return "group dm disabled"
+		}
+	case "direct":
+		if !p.DMEnabled {
+			return "dm disabled"
+		}
+	}
+	if guildID != "" && len(p.GuildEntries) > 0 {
+		g, ok := p.GuildEntries[guildID]
+		if !ok {
+			g, ok = p.GuildEntries["*"]
+		}
+		if !ok || !g.Allow {
+			return "guild not allowed"
+		}
+	}
+	return ""
+}
+
+// stripBotMention removes <@id> / <@!id> mentions of the bot so commands and the agent see plain text.
+func stripBotMention(text, botUserID string) string {
+	if botUserID == "" {
+		return text
 	}
+	text = strings.ReplaceAll(text, "<@"+botUserID+">", "")
+	text = strings.ReplaceAll(text, "<@!"+botUserID+">", "")
+	return strings.TrimSpace(text)
 }
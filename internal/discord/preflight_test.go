@@ -0,0 +1,41 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRefuseReason(t *testing.T) {
+	user := &discordgo.User{ID: "1"}
+	open := PreflightParams{BotUserID: "99", DMEnabled: true, GroupDMEnabled: true}
+	guilds := map[string]GuildEntry{"g1": {ID: "g1", Allow: true}, "g2": {ID: "g2"}}
+	tests := []struct {
+		name     string
+		params   PreflightParams
+		author   *discordgo.User
+		guildID  string
+		chatType string
+		want     string
+	}{
+		{name: "channel", params: open, author: user, guildID: "g1", chatType: "channel"},
+		{name: "direct", params: open, author: user, chatType: "direct"},
+		{name: "own message", params: open, author: &discordgo.User{ID: "99", Bot: true}, guildID: "g1", chatType: "channel", want: "own message"},
+		{name: "other bot", params: open, author: &discordgo.User{ID: "2", Bot: true}, guildID: "g1", chatType: "channel", want: "bot"},
+		{name: "bots allowed", params: PreflightParams{DiscordCfg: &DiscordConfig{AllowBots: true}}, author: &discordgo.User{ID: "2", Bot: true}, guildID: "g1", chatType: "channel"},
+		{name: "dm disabled", params: PreflightParams{GroupDMEnabled: true}, author: user, chatType: "direct", want: "dm disabled"},
+		{name: "group dm disabled", params: PreflightParams{DMEnabled: true}, author: user, chatType: "group", want: "group dm disabled"},
+		{name: "allowed guild", params: PreflightParams{GuildEntries: guilds}, author: user, guildID: "g1", chatType: "thread"},
+		{name: "guild not allowed", params: PreflightParams{GuildEntries: guilds}, author: user, guildID: "g2", chatType: "channel", want: "guild not allowed"},
+		{name: "unlisted guild", params: PreflightParams{GuildEntries: guilds}, author: user, guildID: "g3", chatType: "channel", want: "guild not allowed"},
+		{name: "wildcard guild", params: PreflightParams{GuildEntries: map[string]GuildEntry{"*": {Allow: true}}}, author: user, guildID: "g3", chatType: "channel"},
+		{name: "guild entries ignore dms", params: PreflightParams{DMEnabled: true, GuildEntries: guilds}, author: user, chatType: "direct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := refuseReason(tt.params, tt.author, tt.guildID, tt.chatType); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
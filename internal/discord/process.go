@@ -8,6 +8,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/openclaw/openclaw-go/internal/gateway"
 	"github.com/openclaw/openclaw-go/internal/inbound"
+	"github.com/openclaw/openclaw-go/internal/media"
 )
 
 // ProcessMessage builds inbound context and dispatches to agent.
@@ -18,9 +19,14 @@ func ProcessMessage(ctx context.Context, pre *PreflightContext, opts ProcessOpts
 
 	msg := pre.Message
 	text := pre.MessageText
-	if strings.TrimSpace(text) == "" {
+	files := attachmentFiles(msg.Attachments)
+	if strings.TrimSpace(text) == "" && len(files) == 0 {
 		return nil
 	}
+	if opts.Dispatcher == nil || opts.DispatchInbound == nil {
+		return nil
+	}
+	media.FetchAll(ctx, files, media.LimitsFor(pre.Cfg))
 
 	fromLabel := buildFromLabel(pre)
 	senderLabel := buildSenderLabel(pre)
@@ -28,28 +34,40 @@ func ProcessMessage(ctx context.Context, pre *PreflightContext, opts ProcessOpts
 	msgCtx := &inbound.MsgContext{
 		Body:               text,
 		RawBody:            pre.BaseText,
-		CommandBody:        pre.BaseText,
+		CommandBody:        text,
 		From:               fromLabel,
 		To:                 pre.ChannelID,
 		SessionKey:         pre.Route.SessionKey,
+		AgentID:            pre.Route.AgentID,
 		AccountID:          pre.AccountID,
-		ChatType:           chatType(pre),
+		GuildID:            pre.GuildID,
+		ChatType:           pre.ChatType,
 		ConversationLabel:  fromLabel,
 		SenderName:         senderLabel,
 		SenderId:           pre.Author.ID,
 		SenderUsername:     pre.Author.Username,
+		SenderRoles:        pre.SenderRoles,
 		Provider:           "discord",
 		Surface:            "discord",
 		WasMentioned:       pre.WasMentioned,
 		MessageSid:         msg.ID,
+		Timestamp:          msg.Timestamp.UnixMilli(),
 		CommandAuthorized:  pre.CommandAuthorized,
 		OriginatingChannel: "discord",
 		OriginatingTo:      buildReplyTarget(pre),
 		ReplyChannelID:     pre.ChannelID,
+		Media:              files,
+		RouteInput:         &pre.RouteInput,
 	}
-
-	if opts.Dispatcher == nil || opts.DispatchInbound == nil {
-		return nil
+	if opts.ReplyContext {
+		msgCtx.ReplyTo = replyContext(opts.Session, msg)
+	}
+	if pre.IsGuildMessage && opts.HistoryLimit > 0 {
+		channelID := opts.HistoryChannelID
+		if channelID == "" {
+			channelID = pre.ChannelID
+		}
+		msgCtx.ChannelHistory = channelHistory(opts.Session, channelID, msg.ID, opts.BotUserID, opts.HistoryLimit)
 	}
 	return opts.DispatchInbound(ctx, msgCtx, opts.Dispatcher)
 }
@@ -58,6 +76,33 @@ func ProcessMessage(ctx context.Context, pre *PreflightContext, opts ProcessOpts
 type ProcessOpts struct {
 	DispatchInbound func(ctx context.Context, msgCtx *inbound.MsgContext, d gateway.Dispatcher) error
 	Dispatcher      gateway.Dispatcher
+	// Session fetches reply and history context; nil skips both.
+	Session   *discordgo.Session
+	BotUserID string
+	// ReplyContext adds the replied-to message; HistoryLimit adds that many earlier channel messages.
+	ReplyContext bool
+	HistoryLimit int
+	// HistoryChannelID is where history is read when the reply goes elsewhere (auto-created threads).
+	HistoryChannelID string
+}
+
+// attachmentFiles converts Discord attachments to media files (not yet downloaded).
+func attachmentFiles(atts []*discordgo.MessageAttachment) []media.File {
+	var files []media.File
+	for _, a := range atts {
+		if a == nil {
+			continue
+		}
+		files = append(files, media.File{
+			Name:        a.Filename,
+			ContentType: a.ContentType,
+			Size:        int64(a.Size),
+			URL:         a.URL,
+			Width:       a.Width,
+			Height:      a.Height,
+		})
+	}
+	return files
 }
 
 func buildFromLabel(pre *PreflightContext) string {
@@ -96,13 +141,6 @@ func formatUserTag(u *discordgo.User) string {
 	return u.Username
 }
 
-func chatType(pre *PreflightContext) string {
-	if pre.IsDirectMessage {
-		return "direct"
-	}
-	return "channel"
-}
-
 func buildReplyTarget(pre *PreflightContext) string {
 	if pre.IsDirectMessage {
 		return "user:" + pre.Author.ID
@@ -0,0 +1,136 @@
+package dispatch
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/openclaw/openclaw-go/internal/gateway"
+)
+
+// typingInterval refreshes the Discord typing indicator, which expires after about 10 seconds.
+const typingInterval = 8 * time.Second
+
+// statusTimeout bounds a status reaction update, which may outlive the message context.
+const statusTimeout = 10 * time.Second
+
+// statusEmoji maps activity states to the reactions shown on the triggering message.
+var statusEmoji = map[gateway.ActivityStatus]string{
+	gateway.ActivityQueued:   "⏳",
+	gateway.ActivityThinking: "🤔",
+	gateway.ActivityError:    "⚠️",
+}
+
+// StartTyping keeps the typing indicator alive in channelID until stop is called or ctx ends.
+// stop waits for the refresh loop to exit, so no indicator is sent after it returns.
+func (d *DiscordDispatcher) StartTyping(ctx context.Context, channelID string) (stop func()) {
+	if d.Session == nil || !d.Typing || channelID == "" {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		t := time.NewTicker(typingInterval)
+		defer t.Stop()
+		for {
+			if err := d.Session.ChannelTyping(channelID, discordgo.WithContext(ctx)); err != nil && ctx.Err() == nil {
+				slog.Debug("dispatch: typing", "channel", channelID, "err", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// SetStatus replaces the bot's status reaction on the triggering message; ActivityDone removes it.
+// Reactions go to StatusChannelID when set (the message lives outside the reply channel).
+func (d *DiscordDispatcher) SetStatus(ctx context.Context, channelID, messageID string, status gateway.ActivityStatus) {
+	if d.Session == nil || !d.StatusReactions || messageID == "" {
+		return
+	}
+	if d.StatusChannelID != "" {
+		channelID = d.StatusChannelID
+	}
+	emoji := statusEmoji[status]
+	if emoji == d.status {
+		return
+	}
+	// An error status must still land when the message context was cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusTimeout)
+	defer cancel()
+	if d.status != "" {
+		if err := d.Session.MessageReactionRemove(channelID, messageID, d.status, "@me", discordgo.WithContext(ctx)); err != nil {
+			slog.Debug("dispatch: remove status reaction", "channel", channelID, "err", err)
+		}
+	}
+	d.status = emoji
+	if emoji == "" {
+		return
+	}
+	if err := d.Session.MessageReactionAdd(channelID, messageID, emoji, discordgo.WithContext(ctx)); err != nil {
+		slog.Debug("dispatch: add status reaction", "channel", channelID, "status", status, "err", err)
+	}
+}
+
+// sessionQueue runs messages of one session one at a time, so turns don't interleave in its history.
+type sessionQueue struct {
+	mu    sync.Mutex
+	slots map[string]*sessionSlot
+}
+
+type sessionSlot struct {
+	held chan struct{}
+	refs int // holder plus waiters; the slot is dropped when it reaches zero
+}
+
+var sessionTurns sessionQueue
+
+// acquire waits until key is free or ctx ends. onWait is called once if the session is busy.
+func (q *sessionQueue) acquire(ctx context.Context, key string, onWait func()) (release func(), err error) {
+	q.mu.Lock()
+	if q.slots == nil {
+		q.slots = make(map[string]*sessionSlot)
+	}
+	s := q.slots[key]
+	if s == nil {
+		s = &sessionSlot{held: make(chan struct{}, 1)}
+		q.slots[key] = s
+	}
+	s.refs++
+	q.mu.Unlock()
+
+	unref := func() {
+		q.mu.Lock()
+		if s.refs--; s.refs == 0 {
+			delete(q.slots, key)
+		}
+		q.mu.Unlock()
+	}
+	select {
+	case s.held <- struct{}{}:
+	default:
+		if onWait != nil {
+			onWait()
+		}
+		select {
+		case s.held <- struct{}{}:
+		case <-ctx.Done():
+			unref()
+			return nil, ctx.Err()
+		}
+	}
+	return func() {
+		<-s.held
+		unref()
+	}, nil
+}
@@ -0,0 +1,174 @@
+package dispatch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DiscordMessageLimit is the maximum length of a Discord message in characters.
+const DiscordMessageLimit = 2000
+
+// ChunkMarkdown splits text into chunks of at most limit characters. It prefers paragraph breaks,
+// then line breaks, then spaces, and never cuts a fenced code block without closing the fence at
+// the end of one chunk and reopening it (with the same info string) at the start of the next.
+func ChunkMarkdown(text string, limit int) []string {
+	if limit <= 0 {
+		limit = DiscordMessageLimit
+	}
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+	c := &mdChunker{limit: limit}
+	queue := strings.Split(text, "\n")
+	for len(queue) > 0 {
+		line := queue[0]
+		queue = queue[1:]
+		if again := c.add(line); again != nil {
+			queue = append(again, queue...)
+		}
+	}
+	c.flush(len(c.lines))
+	return c.out
+}
+
+type mdChunker struct {
+	limit  int
+	out    []string
+	lines  []string
+	fences []string // fence opener in effect before each line ("" outside code blocks)
+	size   int      // characters in lines joined by "\n"
+	fence  string   // fence opener in effect after the last line
+	seeded bool     // lines[0] is a reopened fence carried over from the previous chunk
+}
+
+// add appends line, closing the current chunk first when it does not fit. It returns lines that
+// must be added again (the line itself and anything carried over to the next chunk), or nil.
+func (c *mdChunker) add(line string) []string {
+	after := nextFence(c.fence, line)
+	reserve := 0
+	if after != "" {
+		reserve = len("\n```")
+	}
+	need := utf8.RuneCountInString(line)
+	if len(c.lines) > 0 {
+		need++
+	}
+	if c.size+need+reserve <= c.limit {
+		c.push(line, after)
+		return nil
+	}
+	if len(c.lines) == 0 || (c.seeded && len(c.lines) == 1) {
+		// A single line longer than the chunk: split it at a space (or hard).
+		room := c.limit - c.size - reserve
+		if len(c.lines) > 0 {
+			room--
+		}
+		head, tail := splitLongLine(line, room)
+		c.push(head, c.fence)
+		c.flush(len(c.lines))
+		if tail == "" {
+			return nil
+		}
+		return []string{tail}
+	}
+	cut := c.paragraphCut()
+	if last := len(c.lines) - 1; cut == len(c.lines) && last > 0 && c.fences[last] == "" && c.fence != "" {
+		// The chunk ends with a fence opener: start the next chunk with it instead of an empty block.
+		again := []string{c.lines[last], line}
+		c.flush(last)
+		return again
+	}
+	var again []string
+	if cut < len(c.lines) {
+		again = append(again, c.lines[cut+1:]...)
+	}
+	c.flush(cut)
+	return append(again, line)
+}
+
+func (c *mdChunker) push(line, after string) {
+	if len(c.lines) > 0 {
+		c.size++
+	}
+	c.lines = append(c.lines, line)
+	c.fences = append(c.fences, c.fence)
+	c.size += utf8.RuneCountInString(line)
+	c.fence = after
+}
+
+// paragraphCut returns the index of the last blank line outside code blocks in the second half of
+// the chunk, or len(lines) when there is none.
+func (c *mdChunker) paragraphCut() int {
+	for i := len(c.lines) - 1; i > 0 && i >= len(c.lines)/2; i-- {
+		if strings.TrimSpace(c.lines[i]) == "" && c.fences[i] == "" {
+			return i
+		}
+	}
+	return len(c.lines)
+}
+
+// flush emits lines[:n] as a chunk and starts a new one, reopening the code fence if one is open at n.
+func (c *mdChunker) flush(n int) {
+	open := c.fence
+	if n < len(c.lines) {
+		open = c.fences[n]
+	}
+	body := strings.TrimRight(strings.Join(c.lines[:n], "\n"), " \n")
+	if open != "" {
+		body += "\n" + fenceMarker(open)
+	}
+	if strings.TrimSpace(body) != "" && !(c.seeded && n == 1) {
+		c.out = append(c.out, body)
+	}
+	c.lines, c.fences, c.size, c.fence, c.seeded = nil, nil, 0, "", false
+	if open != "" {
+		c.push(open, open)
+		c.seeded = true
+	}
+}
+
+// nextFence returns the fence opener in effect after line, given the one in effect before it.
+func nextFence(open, line string) string {
+	t := strings.TrimSpace(line)
+	if open == "" {
+		if strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			return t
+		}
+		return ""
+	}
+	marker := fenceMarker(open)
+	if strings.HasPrefix(t, marker) && strings.Trim(t, marker[:1]) == "" {
+		return ""
+	}
+	return open
+}
+
+func fenceMarker(open string) string {
+	if strings.HasPrefix(open, "~~~") {
+		return "~~~"
+	}
+	return "```"
+}
+
+// splitLongLine cuts line to at most room characters, preferring the last space in the second half.
+func splitLongLine(line string, room int) (head, tail string) {
+	if room < 1 {
+		room = 1
+	}
+	r := []rune(line)
+	if len(r) <= room {
+		return line, ""
+	}
+	cut := room
+	for i := room; i > room/2; i-- {
+		if r[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	return string(r[:cut]), strings.TrimLeft(string(r[cut:]), " ")
+}
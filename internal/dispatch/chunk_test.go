@@ -0,0 +1,122 @@
+package dispatch
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChunkMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "fits",
+			text:  "  hello\r\nworld  ",
+			limit: 20,
+			want:  []string{"hello\nworld"},
+		},
+		{
+			name:  "empty",
+			text:  " \n ",
+			limit: 20,
+		},
+		{
+			name:  "paragraph break",
+			text:  "first paragraph\n\nsecond one\nthird line",
+			limit: 30,
+			want:  []string{"first paragraph", "second one\nthird line"},
+		},
+		{
+			name:  "fence open across the boundary",
+			text:  "intro\n\n```go\nfunc a() {}\nfunc b() {}\nfunc c() {}\n```\nafter",
+			limit: 30,
+			want:  []string{"intro\n\n```go\nfunc a() {}\n```", "```go\nfunc b() {}\n```", "```go\nfunc c() {}\n```\nafter"},
+		},
+		{
+			name:  "tilde fence",
+			text:  "~~~\naaaa bbbb\ncccc dddd\n~~~",
+			limit: 18,
+			want:  []string{"~~~\naaaa bbbb\n~~~", "~~~\ncccc dddd\n~~~"},
+		},
+		{
+			name:  "fence opener not left at the end of a chunk",
+			text:  "some text\n```\nline one\nline two",
+			limit: 20,
+			want:  []string{"some text", "```\nline one\n```", "```\nline two\n```"},
+		},
+		{
+			name:  "ends inside a fence",
+			text:  "```sh\necho one\necho two",
+			limit: 20,
+			want:  []string{"```sh\necho one\n```", "```sh\necho two\n```"},
+		},
+		{
+			name:  "line longer than the limit",
+			text:  "alpha beta gamma delta epsilon zeta eta theta",
+			limit: 12,
+			want:  []string{"alpha beta", "gamma delta", "epsilon zeta", "eta theta"},
+		},
+		{
+			name:  "line without spaces",
+			text:  "abcdefghijklmnopqrstuvwxyz",
+			limit: 10,
+			want:  []string{"abcdefghij", "klmnopqrst", "uvwxyz"},
+		},
+		{
+			name:  "multibyte runes at the cut",
+			text:  strings.Repeat("你好世界", 4),
+			limit: 5,
+			want:  []string{"你好世界你", "好世界你好", "世界你好世", "界"},
+		},
+		{
+			name:  "multibyte after a space",
+			text:  "ab " + strings.Repeat("é", 8),
+			limit: 6,
+			want:  []string{"ab ééé", "ééééé"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChunkMarkdown(tt.text, tt.limit)
+			if strings.Join(got, "\x00") != strings.Join(tt.want, "\x00") {
+				t.Errorf("got %q\nwant %q", got, tt.want)
+			}
+			for _, c := range got {
+				if n := utf8.RuneCountInString(c); n > tt.limit || !utf8.ValidString(c) {
+					t.Errorf("chunk %q: %d characters (limit %d), valid UTF-8 %v", c, n, tt.limit, utf8.ValidString(c))
+				}
+			}
+		})
+	}
+}
+
+func TestChunkMarkdownDiscordLimit(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Here is the code:\n\n```python\n")
+	for i := 0; i < 300; i++ {
+		b.WriteString("print('line number', " + strings.Repeat("1", i%20) + ")\n")
+	}
+	b.WriteString("```\n\nDone.")
+	chunks := ChunkMarkdown(b.String(), 0)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want several", len(chunks))
+	}
+	for i, c := range chunks {
+		if n := utf8.RuneCountInString(c); n > DiscordMessageLimit {
+			t.Errorf("chunk %d: %d characters", i, n)
+		}
+		if strings.Count(c, "```")%2 != 0 {
+			t.Errorf("chunk %d has an unbalanced fence:\n%s", i, c)
+		}
+		if i > 0 && i < len(chunks)-1 && !strings.HasPrefix(c, "```python\n") {
+			t.Errorf("chunk %d does not reopen the fence: %q", i, c[:20])
+		}
+	}
+	if last := chunks[len(chunks)-1]; !strings.HasSuffix(last, "```\n\nDone.") {
+		t.Errorf("last chunk ends with %q", last[len(last)-20:])
+	}
+}
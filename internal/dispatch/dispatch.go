@@ -2,63 +2,268 @@ package dispatch
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/openclaw/openclaw-go/internal/agent"
+	"github.com/openclaw/openclaw-go/internal/commands"
+	"github.com/openclaw/openclaw-go/internal/config"
 	"github.com/openclaw/openclaw-go/internal/gateway"
 	"github.com/openclaw/openclaw-go/internal/inbound"
 	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/media"
+	"github.com/openclaw/openclaw-go/internal/memory"
+	"github.com/openclaw/openclaw-go/internal/session"
+	"github.com/openclaw/openclaw-go/internal/workspace"
 )
 
 // DiscordDispatcher sends replies via Discord API.
 type DiscordDispatcher struct {
 	Session   *discordgo.Session
 	ChannelID string
+	// ChunkLimit caps each message (0 or above DiscordMessageLimit = DiscordMessageLimit).
+	ChunkLimit int
+	// AttachAboveChunks sends replies that need more chunks than this as a .md file (0 = never).
+	AttachAboveChunks int
+	// MaxUploadBytes caps files sent by SendMedia (0 = DefaultMaxUploadBytes); larger ones become links.
+	MaxUploadBytes int64
+	// ReplyMode decides which chunks are sent as Discord replies to the triggering message.
+	ReplyMode ReplyMode
+	// MentionOnReply pings the author of the message being replied to.
+	MentionOnReply bool
+	// Typing shows the typing indicator while the agent runs.
+	Typing bool
+	// StatusReactions marks the triggering message with queued / thinking / error reactions.
+	StatusReactions bool
+	// StatusChannelID is the channel of the triggering message when replies go elsewhere (auto-created threads).
+	StatusChannelID string
+
+	status string // current status reaction
 }
 
-// SendFinal sends a final reply.
-func (d *DiscordDispatcher) SendFinal(ctx context.Context, channelID, text string) error {
+// ReplyMode controls reply references on outgoing chunks.
+type ReplyMode string
+
+const (
+	ReplyFirst ReplyMode = "first" // only the first chunk replies to the triggering message
+	ReplyAll   ReplyMode = "all"   // every chunk replies to it
+	ReplyOff   ReplyMode = "off"   // plain channel messages
+)
+
+// SendFinal sends a final reply, split into Discord-sized chunks that are sent in order.
+func (d *DiscordDispatcher) SendFinal(ctx context.Context, channelID, replyToID, text string) error {
 	if d.Session == nil {
 		slog.Info("dispatch: no session, would send", "channel", channelID, "text", truncateStr(text, 50))
 		return nil
 	}
-	_, err := d.Session.ChannelMessageSend(channelID, text)
-	return err
+	limit := d.ChunkLimit
+	if limit <= 0 || limit > DiscordMessageLimit {
+		limit = DiscordMessageLimit
+	}
+	chunks := ChunkMarkdown(text, limit)
+	if d.AttachAboveChunks > 0 && len(chunks) > d.AttachAboveChunks {
+		return d.sendAsFile(ctx, channelID, replyToID, text, limit)
+	}
+	for i, chunk := range chunks {
+		msg := &discordgo.MessageSend{Content: chunk}
+		d.applyReply(msg, channelID, replyToID, i)
+		if err := d.send(ctx, channelID, msg); err != nil {
+			return fmt.Errorf("dispatch: send chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+	}
+	return nil
+}
+
+// sendAsFile sends the opening of the reply as a message and the full text as reply.md.
+func (d *DiscordDispatcher) sendAsFile(ctx context.Context, channelID, replyToID, text string, limit int) error {
+	const note = "\n\n(full reply attached as reply.md)"
+	preview := ChunkMarkdown(text, limit-len(note))
+	msg := &discordgo.MessageSend{
+		Content: preview[0] + note,
+		Files: []*discordgo.File{{
+			Name:        "reply.md",
+			ContentType: "text/markdown; charset=utf-8",
+			Reader:      strings.NewReader(text),
+		}},
+	}
+	d.applyReply(msg, channelID, replyToID, 0)
+	return d.send(ctx, channelID, msg)
+}
+
+// applyReply turns chunk index i into a reply to replyToID according to ReplyMode (default ReplyFirst).
+func (d *DiscordDispatcher) applyReply(msg *discordgo.MessageSend, channelID, replyToID string, i int) {
+	if replyToID == "" {
+		return
+	}
+	switch d.ReplyMode {
+	case ReplyOff:
+		return
+	case ReplyAll:
+	default:
+		if i > 0 {
+			return
+		}
+	}
+	failIfNotExists := false
+	msg.Reference = &discordgo.MessageReference{MessageID: replyToID, ChannelID: channelID, FailIfNotExists: &failIfNotExists}
+	msg.AllowedMentions = &discordgo.MessageAllowedMentions{
+		Parse:       []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers},
+		RepliedUser: d.MentionOnReply,
+	}
+}
+
+// send posts one message, waiting out rate limits (bounded by ctx) instead of dropping the chunk.
+func (d *DiscordDispatcher) send(ctx context.Context, channelID string, msg *discordgo.MessageSend) error {
+	const maxAttempts = 5
+	for attempt := 1; ; attempt++ {
+		_, err := d.Session.ChannelMessageSendComplex(channelID, msg,
+			discordgo.WithContext(ctx), discordgo.WithRetryOnRatelimit(false))
+		var rl *discordgo.RateLimitError
+		if err == nil || !errors.As(err, &rl) || attempt == maxAttempts {
+			return err
+		}
+		wait := time.Second
+		if rl.RateLimit != nil && rl.TooManyRequests != nil && rl.RetryAfter > 0 {
+			wait = rl.RetryAfter
+		}
+		slog.Debug("dispatch: rate limited", "channel", channelID, "retryAfter", wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		for _, f := range msg.Files {
+			if r, ok := f.Reader.(io.Seeker); ok {
+				r.Seek(0, io.SeekStart)
+			}
+		}
+	}
+}
+
+// InboundOpts holds the in-process dependencies of DispatchInbound.
+type InboundOpts struct {
+	Cfg *config.Config
+	// LLM 为 agents.defaults.llm_provider 对应的插件，可为 nil（则 agent 回显占位）。DefaultModel 来自配置，可为空。
+	LLM          llm.Plugin
+	DefaultModel string
+	Sessions     *session.Store
+	Workspaces   *workspace.Store
+	Memory       *memory.Store
+	// Commands, if set, intercepts command messages (/reset, /model, ...) before the agent runs.
+	Commands *commands.Router
 }
 
 // DispatchInbound processes the message via in-process agent and dispatches reply.
-// llmPlugin 来自 Runtime.LLM，可为 nil（则 agent 回显占位）。defaultModel 来自配置，可为空。
-func DispatchInbound(ctx context.Context, msgCtx *inbound.MsgContext, dispatcher gateway.Dispatcher, llmPlugin llm.Plugin, defaultModel string) error {
+func DispatchInbound(ctx context.Context, msgCtx *inbound.MsgContext, dispatcher gateway.Dispatcher, opts InboundOpts) error {
 	msgCtx.Finalize()
-	if strings.TrimSpace(msgCtx.BodyForCommands) == "" {
+	if strings.TrimSpace(msgCtx.BodyForCommands) == "" && len(msgCtx.Media) == 0 {
 		slog.Debug("dispatch: empty body, skip")
 		return nil
 	}
 
-	reply, err := agent.Run(ctx, msgCtx, llmPlugin, defaultModel)
-	if err != nil {
-		return err
-	}
-	if reply == "" {
-		return nil
+	var reply agent.Reply
+	handled := false
+	if opts.Commands != nil {
+		var err error
+		reply.Text, handled, err = opts.Commands.Handle(ctx, commands.Env{Cfg: opts.Cfg, Sessions: opts.Sessions}, msgCtx)
+		if err != nil {
+			return err
+		}
 	}
-
 	target := msgCtx.ReplyChannelID
 	if target == "" {
 		target = msgCtx.OriginatingTo
 	}
+	if !handled {
+		if msgCtx.NativeCommand {
+			// Native commands are answered by the command router only, never by the agent.
+			return nil
+		}
+		var err error
+		reply, err = runAgent(ctx, msgCtx, dispatcher, target, opts)
+		if err != nil {
+			return err
+		}
+	}
+	if reply.Text == "" && len(reply.Files) == 0 {
+		return nil
+	}
+
 	if dispatcher != nil && target != "" {
 		slog.Info("dispatch: inbound message",
 			"sessionKey", msgCtx.SessionKey,
 			"from", msgCtx.From,
 			"body", truncateStr(msgCtx.BodyForCommands, 100))
-		return dispatcher.SendFinal(ctx, target, reply)
+		err := sendReply(ctx, dispatcher, target, msgCtx.MessageSid, reply)
+		if err != nil {
+			setStatus(ctx, dispatcher, target, msgCtx.MessageSid, gateway.ActivityError)
+		}
+		return err
 	}
 	return nil
 }
 
+// runAgent runs the agent once the session is free, with activity signals when the dispatcher
+// supports them: queued while an earlier message of the session runs, then typing and thinking
+// until the agent returns. The status is cleared on success and when ctx ends, and set to error otherwise.
+func runAgent(ctx context.Context, msgCtx *inbound.MsgContext, dispatcher gateway.Dispatcher, target string, opts InboundOpts) (agent.Reply, error) {
+	notifier, _ := dispatcher.(gateway.ActivityNotifier)
+	if msgCtx.SessionKey != "" {
+		release, err := sessionTurns.acquire(ctx, msgCtx.SessionKey, func() {
+			setStatus(ctx, dispatcher, target, msgCtx.MessageSid, gateway.ActivityQueued)
+		})
+		if err != nil {
+			setStatus(ctx, dispatcher, target, msgCtx.MessageSid, gateway.ActivityDone)
+			return agent.Reply{}, err
+		}
+		defer release()
+	}
+	setStatus(ctx, dispatcher, target, msgCtx.MessageSid, gateway.ActivityThinking)
+	stopTyping := func() {}
+	if notifier != nil && target != "" {
+		stopTyping = notifier.StartTyping(ctx, target)
+	}
+	reply, err := agent.Run(ctx, msgCtx, agent.RunParams{
+		Cfg:          opts.Cfg,
+		LLM:          opts.LLM,
+		DefaultModel: opts.DefaultModel,
+		Sessions:     opts.Sessions,
+		Workspaces:   opts.Workspaces,
+		Memory:       opts.Memory,
+	})
+	stopTyping()
+	if err != nil && ctx.Err() == nil {
+		setStatus(ctx, dispatcher, target, msgCtx.MessageSid, gateway.ActivityError)
+	} else {
+		setStatus(ctx, dispatcher, target, msgCtx.MessageSid, gateway.ActivityDone)
+	}
+	return reply, err
+}
+
+// sendReply sends text and files, through gateway.MediaSender when the dispatcher has it and as
+// text with links otherwise.
+func sendReply(ctx context.Context, dispatcher gateway.Dispatcher, channelID, replyToID string, reply agent.Reply) error {
+	if len(reply.Files) == 0 {
+		return dispatcher.SendFinal(ctx, channelID, replyToID, reply.Text)
+	}
+	if ms, ok := dispatcher.(gateway.MediaSender); ok {
+		return ms.SendMedia(ctx, channelID, replyToID, reply.Text, reply.Files)
+	}
+	return dispatcher.SendFinal(ctx, channelID, replyToID, media.FallbackText(reply.Text, reply.Files))
+}
+
+// setStatus forwards status to dispatchers implementing gateway.ActivityNotifier.
+func setStatus(ctx context.Context, dispatcher gateway.Dispatcher, channelID, messageID string, status gateway.ActivityStatus) {
+	if n, ok := dispatcher.(gateway.ActivityNotifier); ok && messageID != "" {
+		n.SetStatus(ctx, channelID, messageID, status)
+	}
+}
+
 func truncateStr(s string, n int) string {
 	if len(s) <= n {
 		return s
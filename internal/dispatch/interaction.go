@@ -0,0 +1,39 @@
+package dispatch
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// InteractionDispatcher answers a Discord application command interaction. The interaction must
+// already have a deferred response; replies are sent as follow-up messages.
+type InteractionDispatcher struct {
+	Session     *discordgo.Session
+	Interaction *discordgo.Interaction
+	// Ephemeral makes replies visible only to the invoking user.
+	Ephemeral bool
+	// Sent is true once a reply has been delivered.
+	Sent bool
+}
+
+// SendFinal sends text as follow-ups to the interaction, chunked like DiscordDispatcher; channelID and
+// replyToID are ignored because follow-ups are already tied to the interaction.
+func (d *InteractionDispatcher) SendFinal(ctx context.Context, channelID, replyToID, text string) error {
+	if d.Session == nil || d.Interaction == nil {
+		slog.Info("dispatch: no interaction, would send", "channel", channelID, "text", truncateStr(text, 50))
+		return nil
+	}
+	for _, chunk := range ChunkMarkdown(text, DiscordMessageLimit) {
+		params := &discordgo.WebhookParams{Content: chunk}
+		if d.Ephemeral {
+			params.Flags = discordgo.MessageFlagsEphemeral
+		}
+		if _, err := d.Session.FollowupMessageCreate(d.Interaction, true, params, discordgo.WithContext(ctx)); err != nil {
+			return err
+		}
+		d.Sent = true
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/openclaw/openclaw-go/internal/media"
+)
+
+// DefaultMaxUploadBytes is the upload size limit for bots in guilds without boosts.
+const DefaultMaxUploadBytes = 8 << 20
+
+// discordMaxFiles is the number of attachments Discord accepts per message.
+const discordMaxFiles = 10
+
+// SendMedia sends text (chunked like SendFinal) followed by the files, up to ten per message. Files
+// without data or above MaxUploadBytes are listed as links; if the channel rejects uploads
+// (missing Attach Files permission, payload too large) the batch is sent as links instead.
+func (d *DiscordDispatcher) SendMedia(ctx context.Context, channelID, replyToID, text string, files []media.File) error {
+	if d.Session == nil {
+		slog.Info("dispatch: no session, would send", "channel", channelID, "text", truncateStr(text, 50), "files", len(files))
+		return nil
+	}
+	limit := d.MaxUploadBytes
+	if limit <= 0 {
+		limit = DefaultMaxUploadBytes
+	}
+	var uploads, links []media.File
+	for _, f := range files {
+		if f.Data == nil || int64(len(f.Data)) > limit {
+			links = append(links, f)
+			continue
+		}
+		uploads = append(uploads, f)
+	}
+
+	sent := 0
+	if text = media.FallbackText(text, links); strings.TrimSpace(text) != "" {
+		if err := d.SendFinal(ctx, channelID, replyToID, text); err != nil {
+			return err
+		}
+		sent++
+	}
+	for i := 0; i < len(uploads); i += discordMaxFiles {
+		batch := uploads[i:min(i+discordMaxFiles, len(uploads))]
+		msg := &discordgo.MessageSend{}
+		for _, f := range batch {
+			msg.Files = append(msg.Files, &discordgo.File{Name: f.Name, ContentType: f.ContentType, Reader: bytes.NewReader(f.Data)})
+		}
+		d.applyReply(msg, channelID, replyToID, sent)
+		err := d.send(ctx, channelID, msg)
+		if uploadRejected(err) {
+			slog.Warn("dispatch: upload rejected, sending links", "channel", channelID, "err", err)
+			msg = &discordgo.MessageSend{Content: media.FallbackText("", batch)}
+			d.applyReply(msg, channelID, replyToID, sent)
+			err = d.send(ctx, channelID, msg)
+		}
+		if err != nil {
+			return fmt.Errorf("dispatch: send files: %w", err)
+		}
+		sent++
+	}
+	return nil
+}
+
+// uploadRejected reports whether err means the channel does not accept these files.
+func uploadRejected(err error) bool {
+	var rerr *discordgo.RESTError
+	if !errors.As(err, &rerr) {
+		return false
+	}
+	if rerr.Response != nil && rerr.Response.StatusCode == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	return rerr.Message != nil &&
+		(rerr.Message.Code == discordgo.ErrCodeMissingPermissions || rerr.Message.Code == discordgo.ErrCodeRequestEntityTooLarge)
+}
@@ -0,0 +1,199 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+// DefaultReloadInterval is how often Reloader checks the config file for changes.
+const DefaultReloadInterval = 2 * time.Second
+
+// restartOnly are settings read once at startup; changes to them are logged but need a restart.
+// providers.<id> is applied on reload through Reloader.Apply, except for the embeddings provider,
+// and commands.commands.<name>.enabled decides the native commands registered at startup (see
+// NeedsRestart).
+var restartOnly = []string{
+	"session.store_dir",
+	"memory",
+	"agents.defaults.workspace_root",
+	"agents.defaults.workspace_max_chars",
+	"commands.enabled",
+	"commands.native",
+	"channels.discord.token",
+	"channels.discord.accounts",
+}
+
+// Reloader watches the config file, along with the files it includes or is overlaid with, and swaps
+// new versions into a Runtime. A config that fails to load or validate is rejected and the running
+// one stays in place.
+type Reloader struct {
+	Path    string
+	Runtime *Runtime
+	// Load reads and validates the file.
+	Load func(path string) (*config.Config, error)
+	// Apply, when set, is called with every config that is swapped in, to update state built from it
+	// (e.g. LLM plugin credentials).
+	Apply func(cfg *config.Config)
+	// Watch lists more files whose changes trigger a reload, e.g. the secrets file.
+	Watch []string
+	// Interval between file checks (0 = DefaultReloadInterval).
+	Interval time.Duration
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// Run polls the file until ctx ends. A change is reloaded once the file has stayed the same for one
+// interval, so a file still being written by an editor is not picked up half-way.
+func (r *Reloader) Run(ctx context.Context) {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	r.mu.Lock()
+	r.modTime, r.size = r.stat()
+	r.mu.Unlock()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		r.mu.Lock()
+		settled := false
+		if mt, size := r.stat(); !mt.IsZero() && (!mt.Equal(r.modTime) || size != r.size) {
+			r.modTime, r.size, pending = mt, size, true
+		} else if pending {
+			settled, pending = true, false
+		}
+		r.mu.Unlock()
+		if settled {
+			r.Reload("file changed")
+		}
+	}
+}
+
+// Reload loads the file now and swaps it in if it is valid; reason is logged (e.g. "SIGHUP").
+// It reports whether the new config was applied.
+func (r *Reloader) Reload(reason string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fi, err := os.Stat(r.Path); err != nil || fi.Size() == 0 {
+		if err == nil {
+			err = errors.New("file is empty")
+		}
+		slog.Warn("config: reload skipped, keeping current config", "path", r.Path, "reason", reason, "err", err)
+		return false
+	}
+	cfg, err := r.Load(r.Path)
+	if err != nil {
+		slog.Error("config: reload rejected, keeping current config", "path", r.Path, "reason", reason, "err", err)
+		return false
+	}
+	old := r.Runtime.Config()
+	changes := config.Diff(old, cfg)
+	// Swap even when nothing changed: the new config may include a different set of files to watch.
+	r.Runtime.SetConfig(cfg)
+	if r.Apply != nil {
+		r.Apply(cfg)
+	}
+	r.modTime, r.size = r.stat()
+	if len(changes) == 0 {
+		slog.Info("config: reloaded, no changes", "path", r.Path, "reason", reason)
+		return true
+	}
+	var restart []string
+	for _, c := range changes {
+		if NeedsRestart(cfg, c.Path) || NeedsRestart(old, c.Path) {
+			slog.Warn("config: "+c.String()+" (takes effect after restart)", "path", r.Path)
+			restart = append(restart, c.Path)
+		} else {
+			slog.Info("config: "+c.String(), "path", r.Path)
+		}
+	}
+	slog.Info("config: reloaded", "path", r.Path, "reason", reason, "changes", len(changes))
+	if len(restart) > 0 {
+		slog.Warn("config: restart required to apply some changes", "path", r.Path, "settings", strings.Join(restart, ", "))
+	}
+	return true
+}
+
+// stat returns the newest modification time and the total size of the watched files.
+func (r *Reloader) stat() (time.Time, int64) {
+	files := append([]string{r.Path}, r.Watch...)
+	if cfg := r.Runtime.Config(); cfg != nil {
+		files = append(files, cfg.Files()...)
+	}
+	var latest time.Time
+	var size int64
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+		size += fi.Size()
+	}
+	return latest, size
+}
+
+// NeedsRestart reports whether a change to the setting at path only takes effect after a restart,
+// including a section added or removed as a whole that holds such a setting in cfg. Its
+// memory.embeddings.provider makes that provider's settings restart-only, and its
+// commands.commands entries their enabled flag.
+func NeedsRestart(cfg *config.Config, path string) bool {
+	return restartSetting(cfg, path) != ""
+}
+
+// RestartOnlySettings lists the restart-only settings cfg's files set, e.g. for config check.
+func RestartOnlySettings(cfg *config.Config) []string {
+	var out []string
+	for _, p := range restartSettings(cfg) {
+		if cfg.Sets(p) {
+			out = append(out, p)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// restartSetting returns the restart-only setting path falls under or contains, or "".
+func restartSetting(cfg *config.Config, path string) string {
+	for _, p := range restartSettings(cfg) {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+			return p
+		}
+		if strings.HasPrefix(p, path+".") && cfg != nil && cfg.Sets(p) {
+			return p
+		}
+	}
+	return ""
+}
+
+func restartSettings(cfg *config.Config) []string {
+	if cfg == nil {
+		return restartOnly
+	}
+	out := slices.Clip(restartOnly)
+	if id := cfg.Memory.Embeddings.Provider; id != "" {
+		out = append(out, "providers."+id)
+	}
+	for name := range cfg.Commands.Commands {
+		out = append(out, "commands.commands."+name+".enabled")
+	}
+	return out
+}
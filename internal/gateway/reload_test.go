@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+func loadConfig(t *testing.T, doc string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "openclaw.yaml")
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadEnv(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestNeedsRestart(t *testing.T) {
+	cfg := loadConfig(t, `
+memory:
+  embeddings:
+    provider: openai
+providers:
+  openai:
+    base_url: https://example.com/v1
+commands:
+  enabled: true
+  commands:
+    reset:
+      enabled: false
+    model:
+      users: ["1"]
+`)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"commands.enabled", true},
+		{"commands.native", true},
+		{"commands.commands.reset.enabled", true},
+		{"commands.commands.reset", true},
+		{"commands.commands", true},
+		{"commands", true},
+		{"commands.commands.model.users", false},
+		{"commands.commands.model", false},
+		{"commands.owner_ids", false},
+		{"memory.dir", true},
+		{"providers.openai", true},
+		{"providers.kimi", false},
+		{"providers", true},
+		{"bindings[0].agent_id", false},
+		{"agents.list[0].system_prompt", false},
+	}
+	for _, tt := range tests {
+		if got := NeedsRestart(cfg, tt.path); got != tt.want {
+			t.Errorf("NeedsRestart(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if NeedsRestart(nil, "commands") {
+		t.Error("NeedsRestart(nil, commands) = true")
+	}
+}
+
+func TestRestartOnlySettings(t *testing.T) {
+	cfg := loadConfig(t, `
+session:
+  store_dir: /tmp/sessions
+commands:
+  enabled: false
+  commands:
+    reset:
+      enabled: false
+    model:
+      users: ["1"]
+`)
+	got := strings.Join(RestartOnlySettings(cfg), " ")
+	if want := "commands.commands.reset.enabled commands.enabled session.store_dir"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
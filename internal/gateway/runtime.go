@@ -2,24 +2,69 @@ package gateway
 
 import (
 	"context"
+	"sync/atomic"
 
+	"github.com/openclaw/openclaw-go/internal/commands"
 	"github.com/openclaw/openclaw-go/internal/config"
 	"github.com/openclaw/openclaw-go/internal/inbound"
-	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/media"
+	"github.com/openclaw/openclaw-go/internal/session"
 )
 
 // Runtime is the gateway runtime passed to channel plugins (like TS PluginRuntime).
 // Channels use it to dispatch inbound messages and get config.
 type Runtime struct {
-	Config *config.Config
-	// LLM 为当前使用的 LLM 插件，由 main 根据配置注入；nil 时不调用大模型（如 echo 占位）。
-	LLM llm.Plugin
+	// config is the active configuration, replaced as a whole on reload (see Reloader).
+	config atomic.Pointer[config.Config]
+	// Sessions holds per-session history, overrides and usage.
+	Sessions *session.Store
+	// Commands is the chat command router; channels also use it to register native commands.
+	Commands *commands.Router
 	// DispatchInbound is called when a channel receives a message. The dispatcher
 	// sends replies back to the originating channel (in-process function call).
 	DispatchInbound func(ctx context.Context, msgCtx *inbound.MsgContext, dispatcher Dispatcher) error
 }
 
+// Config returns the active configuration. Channels read it once per inbound message, so a reload
+// applies from the next message on and never changes the config under a message being handled.
+func (r *Runtime) Config() *config.Config {
+	return r.config.Load()
+}
+
+// SetConfig replaces the active configuration.
+func (r *Runtime) SetConfig(cfg *config.Config) {
+	r.config.Store(cfg)
+}
+
 // Dispatcher sends replies to a channel. Implemented per-channel (e.g. DiscordDispatcher).
 type Dispatcher interface {
-	SendFinal(ctx context.Context, channelID, text string) error
+	// SendFinal sends text to channelID. replyToID is the inbound message being answered
+	// (MsgContext.MessageSid); channels that support replies reference it, others ignore it.
+	SendFinal(ctx context.Context, channelID, replyToID, text string) error
+}
+
+// MediaSender is an optional Dispatcher capability for sending files with a reply. Dispatchers
+// without it get the text plus media.FallbackText (links or notes) through SendFinal.
+type MediaSender interface {
+	// SendMedia sends text and files to channelID; files the channel cannot accept are sent as links.
+	SendMedia(ctx context.Context, channelID, replyToID, text string, files []media.File) error
+}
+
+// ActivityStatus is a coarse progress state shown on the triggering message.
+type ActivityStatus string
+
+const (
+	ActivityQueued   ActivityStatus = "queued"   // waiting for an earlier message of the same session
+	ActivityThinking ActivityStatus = "thinking" // the agent is running
+	ActivityDone     ActivityStatus = "done"     // finished; clears the status
+	ActivityError    ActivityStatus = "error"    // the agent failed
+)
+
+// ActivityNotifier is an optional Dispatcher capability for "the bot is working" signals.
+// DispatchInbound uses it when the dispatcher implements it.
+type ActivityNotifier interface {
+	// StartTyping shows a typing indicator in channelID until stop is called or ctx ends.
+	StartTyping(ctx context.Context, channelID string) (stop func())
+	// SetStatus marks the inbound message messageID with status, replacing the previous one.
+	SetStatus(ctx context.Context, channelID, messageID string, status ActivityStatus)
 }
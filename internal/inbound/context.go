@@ -2,6 +2,9 @@ package inbound
 
 import (
 	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/media"
+	"github.com/openclaw/openclaw-go/internal/routing"
 )
 
 // MsgContext is the finalized inbound message context (from FinalizedMsgContext in TS).
@@ -14,22 +17,45 @@ type MsgContext struct {
 	From              string
 	To                string
 	SessionKey        string
+	AgentID           string
 	AccountID         string
+	GuildID           string // Discord guild (server) ID; empty outside guilds
 	ChatType          string // "direct" or "channel"
 	ConversationLabel string
 	SenderName        string
 	SenderId          string
 	SenderUsername    string
+	// SenderRoles are the sender's role IDs in the conversation (Discord guild roles), used for command authorization.
+	SenderRoles       []string
 	Provider          string
 	Surface           string
 	WasMentioned      bool
 	MessageSid        string
-	Timestamp         int64
+	Timestamp         int64 // Unix milliseconds when the message was sent
 	CommandAuthorized bool
+	// NativeCommand marks a channel-native command (Discord slash command); it never reaches the agent.
+	NativeCommand     bool
 	OriginatingChannel string
 	OriginatingTo     string
 	// ReplyChannelID is the Discord channel ID to send reply (for Discord dispatcher).
 	ReplyChannelID string
+	// Media holds the message's attachments, already downloaded (or marked skipped) by the channel.
+	Media []media.File
+	// ReplyTo is the message this one replies to, if any.
+	ReplyTo *QuotedMessage
+	// ChannelHistory holds earlier channel messages for context, oldest first (empty unless configured).
+	ChannelHistory []QuotedMessage
+	// RouteInput is what the channel resolved the route from (without Cfg), so /route can explain it.
+	RouteInput *routing.ResolveAgentRouteInput
+}
+
+// QuotedMessage is another message shown to the agent as context.
+type QuotedMessage struct {
+	ID         string
+	SenderName string
+	SenderId   string
+	Body       string
+	Timestamp  int64 // Unix milliseconds
 }
 
 // Finalize normalizes and finalizes the context.
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Embedder 把向量插件与模型绑定，供 memory 等按接口使用。
+type Embedder struct {
+	Plugin EmbeddingsPlugin
+	Name   string // 模型名，空则用插件默认
+}
+
+// Embed 返回 texts 的向量。
+func (e Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := e.Plugin.Embed(ctx, &EmbeddingsRequest{Model: e.Name, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Vectors) != len(texts) {
+		return nil, fmt.Errorf("llm: %s returned %d vectors for %d inputs", e.Plugin.ID(), len(resp.Vectors), len(texts))
+	}
+	return resp.Vectors, nil
+}
+
+// Model 标识向量空间（插件 id + 模型），模型变化后旧向量需重新计算。
+func (e Embedder) Model() string {
+	if e.Name == "" {
+		return string(e.Plugin.ID())
+	}
+	return string(e.Plugin.ID()) + "/" + e.Name
+}
+
+// HashEmbeddingsID 是 HashEmbeddings 的 id。
+const HashEmbeddingsID ProviderID = "hash"
+
+// HashEmbeddings 是确定性的离线向量插件：对词（中日韩文本按字与二元组）做特征哈希并归一化。
+// 不理解同义改写，适合开发、测试和无网络环境；同样输入总是得到同样向量。
+type HashEmbeddings struct {
+	Dims int // 向量维度，默认 256
+}
+
+// ID 返回 "hash"。
+func (HashEmbeddings) ID() ProviderID {
+	return HashEmbeddingsID
+}
+
+// Embed 为每段文本计算特征哈希向量。
+func (h HashEmbeddings) Embed(_ context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	dims := h.Dims
+	if dims <= 0 {
+		dims = 256
+	}
+	out := &EmbeddingsResponse{Vectors: make([][]float32, len(req.Input))}
+	for i, text := range req.Input {
+		v := make([]float32, dims)
+		for _, term := range hashTerms(text) {
+			f := fnv.New32a()
+			f.Write([]byte(term))
+			sum := f.Sum32()
+			sign := float32(1)
+			if sum&1 == 1 {
+				sign = -1
+			}
+			v[int(sum>>1)%dims] += sign
+		}
+		var norm float64
+		for _, x := range v {
+			norm += float64(x) * float64(x)
+		}
+		if norm > 0 {
+			scale := float32(1 / math.Sqrt(norm))
+			for j := range v {
+				v[j] *= scale
+			}
+		}
+		out.Vectors[i] = v
+	}
+	return out, nil
+}
+
+// hashTerms splits text into lowercase words, and CJK runs into characters and bigrams.
+func hashTerms(text string) []string {
+	var terms []string
+	var word []rune
+	var prev rune
+	for _, r := range strings.ToLower(text) {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			if len(word) > 0 {
+				terms, word = append(terms, string(word)), word[:0]
+			}
+			terms = append(terms, string(r))
+			if prev != 0 {
+				terms = append(terms, string([]rune{prev, r}))
+			}
+			prev = r
+			continue
+		}
+		prev = 0
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+		} else if len(word) > 0 {
+			terms, word = append(terms, string(word)), word[:0]
+		}
+	}
+	if len(word) > 0 {
+		terms = append(terms, string(word))
+	}
+	return terms
+}
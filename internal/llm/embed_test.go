@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// dot is the cosine similarity of normalized vectors.
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func TestHashEmbeddings(t *testing.T) {
+	e := Embedder{Plugin: HashEmbeddings{}}
+	texts := []string{"likes strong coffee", "Coffee, strong!", "lives in Berlin", "喜欢喝咖啡", "咖啡", ""}
+	vs, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := e.Embed(context.Background(), texts)
+	for i, v := range vs {
+		if len(v) != 256 {
+			t.Fatalf("%q: %d dims, want 256", texts[i], len(v))
+		}
+		for j := range v {
+			if v[j] != again[i][j] {
+				t.Fatalf("%q: not deterministic", texts[i])
+			}
+		}
+		if n := dot(v, v); texts[i] != "" && math.Abs(n-1) > 1e-6 {
+			t.Errorf("%q: norm² %v, want 1", texts[i], n)
+		}
+	}
+	if got := dot(vs[0], vs[1]); got < 0.8 {
+		t.Errorf("shared words: similarity %v, want >= 0.8", got)
+	}
+	if got := dot(vs[0], vs[2]); math.Abs(got) > 0.3 {
+		t.Errorf("unrelated: similarity %v, want about 0", got)
+	}
+	if got := dot(vs[3], vs[4]); got < 0.4 {
+		t.Errorf("CJK substring: similarity %v, want >= 0.4", got)
+	}
+}
+
+func TestEmbedderModel(t *testing.T) {
+	if got := (Embedder{Plugin: HashEmbeddings{}}).Model(); got != "hash" {
+		t.Errorf("got %q", got)
+	}
+	if got := (Embedder{Plugin: HashEmbeddings{Dims: 8}, Name: "small"}).Model(); got != "hash/small" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -7,10 +7,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/openclaw/openclaw-go/internal/llm"
+	"github.com/openclaw/openclaw-go/internal/llm/openai"
 )
 
 const (
@@ -20,7 +21,7 @@ const (
 	DefaultBaseURL = "https://api.moonshot.cn/v1"
 	// DefaultModel 默认模型，可使用 kimi-k2-turbo-preview / kimi-k2-thinking 等。
 	DefaultModel = "kimi-k2-turbo-preview"
-	// EnvAPIKey 环境变量名，用于读取 Kimi API Key。
+	// EnvAPIKey 为 Kimi API Key 的默认环境变量名；由调用方经配置解析后传入 APIKey，插件本身不读环境变量。
 	EnvAPIKey = "MOONSHOT_API_KEY"
 )
 
@@ -28,7 +29,7 @@ const (
 type Plugin struct {
 	BaseURL string // 为空则用 DefaultBaseURL
 	Model   string // 为空则用 DefaultModel
-	APIKey  string // 为空则从 EnvAPIKey 环境变量读取
+	APIKey  string // 必填，来自配置 providers.kimi.api_key 或 EnvAPIKey
 	Client  *http.Client
 }
 
@@ -41,10 +42,7 @@ func (p *Plugin) ID() llm.ProviderID {
 func (p *Plugin) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
 	apiKey := p.APIKey
 	if apiKey == "" {
-		apiKey = os.Getenv(EnvAPIKey)
-	}
-	if apiKey == "" {
-		return nil, fmt.Errorf("llm/kimi: API key required (set %s or Plugin.APIKey)", EnvAPIKey)
+		return nil, fmt.Errorf("llm/kimi: API key required (set providers.kimi.api_key or %s)", EnvAPIKey)
 	}
 
 	baseURL := p.BaseURL
@@ -65,6 +63,9 @@ func (p *Plugin) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatRespo
 		Temperature: 0.6,
 		MaxTokens:   2048,
 	}
+	if len(req.Tools) > 0 {
+		payload.Tools = llm.WireTools(req.Tools)
+	}
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("llm/kimi: marshal request: %w", err)
@@ -100,23 +101,51 @@ func (p *Plugin) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatRespo
 		return nil, fmt.Errorf("llm/kimi: unmarshal response: %w", err)
 	}
 	if len(kimiResp.Choices) == 0 {
-		return &llm.ChatResponse{Content: ""}, nil
+		return &llm.ChatResponse{Content: "", Usage: kimiResp.Usage}, nil
+	}
+	msg := kimiResp.Choices[0].Message
+	calls, err := llm.DecodeToolCalls(msg.ToolCalls)
+	if err != nil {
+		return nil, fmt.Errorf("llm/kimi: decode tool calls: %w", err)
+	}
+	return &llm.ChatResponse{Content: msg.Content, Usage: kimiResp.Usage, ToolCalls: calls}, nil
+}
+
+// SupportsInput 报告模型是否接受图片：Kimi 的视觉模型名含 "vision"（如 moonshot-v1-8k-vision-preview），kimi-latest 也支持。
+// Kimi 不接受 file 内容段。
+func (p *Plugin) SupportsInput(model, partType string) bool {
+	if partType != llm.PartImageURL {
+		return false
 	}
-	content := kimiResp.Choices[0].Message.Content
-	return &llm.ChatResponse{Content: content}, nil
+	if model == "" {
+		model = p.Model
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+	return strings.Contains(model, "vision") || strings.HasPrefix(model, "kimi-latest")
+}
+
+// NewEmbeddings 返回调用 Kimi（月之暗面）OpenAI 兼容 /embeddings 接口的向量插件，id 为 "kimi"；
+// 模型需在配置中指定（memory.embeddings.model）。
+func NewEmbeddings() *openai.Embeddings {
+	return &openai.Embeddings{Provider: ProviderID, BaseURL: DefaultBaseURL, APIKeyEnv: EnvAPIKey}
 }
 
 type kimiRequest struct {
-	Model       string       `json:"model"`
-	Messages    []llm.Message `json:"messages"`
-	Temperature float64      `json:"temperature"`
-	MaxTokens   int          `json:"max_tokens"`
+	Model       string           `json:"model"`
+	Messages    []llm.Message    `json:"messages"`
+	Temperature float64          `json:"temperature"`
+	MaxTokens   int              `json:"max_tokens"`
+	Tools       []map[string]any `json:"tools,omitempty"`
 }
 
 type kimiResponse struct {
 	Choices []struct {
 		Message struct {
-			Content string `json:"content"`
+			Content   string          `json:"content"`
+			ToolCalls json.RawMessage `json:"tool_calls"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage llm.Usage `json:"usage"`
 }
@@ -0,0 +1,113 @@
+// Package openai implements llm plugins for OpenAI-compatible HTTP APIs.
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/llm"
+)
+
+const (
+	// ProviderID 是 OpenAI 向量插件的默认 id。
+	ProviderID llm.ProviderID = "openai"
+	// DefaultBaseURL OpenAI API 基础地址。
+	DefaultBaseURL = "https://api.openai.com/v1"
+	// DefaultEmbeddingModel 默认向量模型。
+	DefaultEmbeddingModel = "text-embedding-3-small"
+	// EnvAPIKey 为 OpenAI API Key 的默认环境变量名；由调用方经配置解析后传入 APIKey，插件本身不读环境变量。
+	EnvAPIKey = "OPENAI_API_KEY"
+)
+
+// Embeddings 实现 llm.EmbeddingsPlugin，调用任意 OpenAI 兼容的 POST {BaseURL}/embeddings。
+type Embeddings struct {
+	Provider  llm.ProviderID // 为空则为 "openai"
+	BaseURL   string         // 为空则用 DefaultBaseURL
+	Model     string         // 为空则用 DefaultEmbeddingModel
+	APIKey    string         // 必填，由调用方从配置解析
+	APIKeyEnv string         // 调用方默认读取 API Key 的环境变量名，为空则为 EnvAPIKey
+	Client    *http.Client
+}
+
+// ID 返回 Provider（默认 "openai"）。
+func (e *Embeddings) ID() llm.ProviderID {
+	if e.Provider == "" {
+		return ProviderID
+	}
+	return e.Provider
+}
+
+// Embed 调用 /embeddings，按输入顺序返回向量。
+func (e *Embeddings) Embed(ctx context.Context, req *llm.EmbeddingsRequest) (*llm.EmbeddingsResponse, error) {
+	apiKey := e.APIKey
+	if apiKey == "" {
+		env := e.APIKeyEnv
+		if env == "" {
+			env = EnvAPIKey
+		}
+		return nil, fmt.Errorf("llm/%s: API key required (set providers.%s.api_key, memory.embeddings.api_key or %s)", e.ID(), e.ID(), env)
+	}
+	baseURL := e.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	model := req.Model
+	if model == "" {
+		model = e.Model
+	}
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+
+	body, err := json.Marshal(map[string]any{"model": model, "input": req.Input})
+	if err != nil {
+		return nil, fmt.Errorf("llm/%s: marshal request: %w", e.ID(), err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("llm/%s: new request: %w", e.ID(), err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("llm/%s: do request: %w", e.ID(), err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("llm/%s: read body: %w", e.ID(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("llm/%s: api error status=%d body=%s", e.ID(), resp.StatusCode, string(respBody))
+	}
+
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage llm.Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return nil, fmt.Errorf("llm/%s: unmarshal response: %w", e.ID(), err)
+	}
+	sort.Slice(out.Data, func(i, j int) bool { return out.Data[i].Index < out.Data[j].Index })
+	vectors := make([][]float32, 0, len(out.Data))
+	for _, d := range out.Data {
+		vectors = append(vectors, d.Embedding)
+	}
+	return &llm.EmbeddingsResponse{Vectors: vectors, Usage: out.Usage}, nil
+}
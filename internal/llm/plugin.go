@@ -1,6 +1,12 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/media"
+)
 
 // ProviderID 标识一个 LLM 插件（如 "kimi"、"openai"）。
 type ProviderID string
@@ -9,17 +15,210 @@ type ProviderID string
 type Message struct {
 	Role    string `json:"role"`    // "system", "user", "assistant"
 	Content string `json:"content"`
+	// Parts 非空时作为多段内容（文本 + 图片 + 文件）发送，取代 Content；JSON 中编码为 content 数组。
+	Parts []ContentPart `json:"-"`
+	// ToolCalls 为 assistant 消息请求的工具调用。
+	ToolCalls []ToolCall `json:"-"`
+	// ToolCallID 为 role "tool" 消息所回应的调用 id。
+	ToolCallID string `json:"-"`
+}
+
+// Tool 描述一个可供模型调用的函数工具（OpenAI tools 格式）。
+type Tool struct {
+	Name        string `json:"name"` // 仅限字母、数字、_ 与 -
+	Description string `json:"description"`
+	// Parameters 为参数的 JSON Schema。
+	Parameters map[string]any `json:"parameters"`
+}
+
+// ToolCall 为模型发起的一次工具调用；Arguments 为 JSON 字符串。
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+type wireToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// WireTools 把工具编码为 OpenAI 兼容请求中的 tools 字段。
+func WireTools(tools []Tool) []map[string]any {
+	out := make([]map[string]any, 0, len(tools))
+	for _, t := range tools {
+		out = append(out, map[string]any{"type": "function", "function": t})
+	}
+	return out
+}
+
+// ContentPart 是多段消息中的一段（OpenAI content 数组格式）。
+type ContentPart struct {
+	Type     string    `json:"type"` // PartText, PartImageURL 或 PartFile
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+	File     *FileData `json:"file,omitempty"`
+}
+
+// Content part types.
+const (
+	PartText     = "text"
+	PartImageURL = "image_url"
+	PartFile     = "file"
+)
+
+// ImageURL 为图片地址，可为 http(s) URL 或 data:image/...;base64 URL。
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// FileData 为内联文件，FileData 为 data URL（base64）。
+type FileData struct {
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data"`
+}
+
+// TextPart 返回文本段。
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: PartText, Text: text}
+}
+
+// ImagePart 返回图片段。
+func ImagePart(url string) ContentPart {
+	return ContentPart{Type: PartImageURL, ImageURL: &ImageURL{URL: url}}
+}
+
+// FilePart 返回文件段。
+func FilePart(filename, dataURL string) ContentPart {
+	return ContentPart{Type: PartFile, File: &FileData{Filename: filename, FileData: dataURL}}
+}
+
+// Text 返回消息的文本内容：Parts 非空时为各文本段拼接。
+func (m Message) Text() string {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	var texts []string
+	for _, p := range m.Parts {
+		if p.Type == PartText {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+type wireMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	ToolCalls  []wireToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// MarshalJSON 在 Parts 非空时把 content 编码为数组，否则为字符串。
+func (m Message) MarshalJSON() ([]byte, error) {
+	var content any = m.Content
+	if len(m.Parts) > 0 {
+		content = m.Parts
+	}
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	w := wireMessage{Role: m.Role, Content: raw, ToolCallID: m.ToolCallID}
+	for _, c := range m.ToolCalls {
+		wc := wireToolCall{ID: c.ID, Type: "function"}
+		wc.Function.Name, wc.Function.Arguments = c.Name, c.Arguments
+		w.ToolCalls = append(w.ToolCalls, wc)
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON 接受字符串或数组形式的 content。
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var w wireMessage
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*m = Message{Role: w.Role, ToolCallID: w.ToolCallID, ToolCalls: decodeToolCalls(w.ToolCalls)}
+	if len(w.Content) == 0 || string(w.Content) == "null" {
+		return nil
+	}
+	if w.Content[0] == '[' {
+		return json.Unmarshal(w.Content, &m.Parts)
+	}
+	return json.Unmarshal(w.Content, &m.Content)
+}
+
+// DecodeToolCalls 解析 OpenAI 兼容响应中的 tool_calls 字段。
+func DecodeToolCalls(raw json.RawMessage) ([]ToolCall, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var wire []wireToolCall
+	if err := json.Unmarshal(raw, &wire); err != nil {
+		return nil, err
+	}
+	return decodeToolCalls(wire), nil
+}
+
+func decodeToolCalls(wire []wireToolCall) []ToolCall {
+	var out []ToolCall
+	for _, c := range wire {
+		out = append(out, ToolCall{ID: c.ID, Name: c.Function.Name, Arguments: c.Function.Arguments})
+	}
+	return out
 }
 
 // ChatRequest 请求 LLM 完成一轮对话。
 type ChatRequest struct {
 	Model    string    `json:"model,omitempty"`    // 可选，不填则用插件默认
 	Messages []Message `json:"messages"`
+	// Tools 为可调用的工具；插件不支持工具时忽略。
+	Tools []Tool `json:"-"`
 }
 
 // ChatResponse LLM 回复。
 type ChatResponse struct {
 	Content string `json:"content"`
+	// Usage 为本轮 token 用量，插件未返回时为零值。
+	Usage Usage `json:"usage"`
+	// Files 为模型生成的文件/图片（如图像生成结果），随回复一起发送；Data 或 URL 至少有一个。
+	Files []media.File `json:"-"`
+	// ToolCalls 非空时表示模型请求调用工具，调用方执行后把结果作为 role "tool" 消息继续对话。
+	ToolCalls []ToolCall `json:"-"`
+}
+
+// Usage 记录一次调用的 token 用量（与 OpenAI usage 字段一致）。
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add 累加 o 的用量。
+func (u *Usage) Add(o Usage) {
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.TotalTokens += o.TotalTokens
+}
+
+// InputSupporter 由能判断模型是否接受非文本输入的插件实现（可选）。
+type InputSupporter interface {
+	// SupportsInput 报告 model 是否接受 partType（PartImageURL、PartFile）类型的内容段；model 为空表示插件默认模型。
+	SupportsInput(model, partType string) bool
+}
+
+// SupportsInput 报告 p 的 model 是否接受 partType；插件未实现 InputSupporter 时视为仅支持文本。
+func SupportsInput(p Plugin, model, partType string) bool {
+	if s, ok := p.(InputSupporter); ok {
+		return s.SupportsInput(model, partType)
+	}
+	return false
 }
 
 // Plugin 是 LLM 插件接口。与 channel 插件并列，互不耦合；后续切换大模型只需换用不同插件。
@@ -28,3 +227,23 @@ type Plugin interface {
 	// Chat 根据消息列表生成回复。由 agent 调用，不依赖具体 HTTP/SDK 实现。
 	Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error)
 }
+
+// EmbeddingsPlugin 把文本转换为向量（OpenAI /embeddings 兼容），用于语义检索。与 Plugin 相互独立。
+type EmbeddingsPlugin interface {
+	ID() ProviderID
+	// Embed 为 req.Input 中的每段文本返回一个向量，顺序一致。
+	Embed(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error)
+}
+
+// EmbeddingsRequest 请求文本向量。
+type EmbeddingsRequest struct {
+	// Model 可选，不填则用插件默认。
+	Model string
+	Input []string
+}
+
+// EmbeddingsResponse 为向量结果。
+type EmbeddingsResponse struct {
+	Vectors [][]float32
+	Usage   Usage
+}
@@ -3,8 +3,9 @@ package llm
 import "sync"
 
 var (
-	plugins   = make(map[ProviderID]Plugin)
-	pluginsMu sync.RWMutex
+	plugins    = make(map[ProviderID]Plugin)
+	embeddings = make(map[ProviderID]EmbeddingsPlugin)
+	pluginsMu  sync.RWMutex
 )
 
 // Register 注册一个 LLM 插件。
@@ -31,3 +32,28 @@ func List() []Plugin {
 	}
 	return out
 }
+
+// RegisterEmbeddings 注册一个向量插件。
+func RegisterEmbeddings(p EmbeddingsPlugin) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	embeddings[p.ID()] = p
+}
+
+// GetEmbeddings 按 id 返回已注册的向量插件，未找到返回 nil。
+func GetEmbeddings(id ProviderID) EmbeddingsPlugin {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	return embeddings[id]
+}
+
+// ListEmbeddings 返回所有已注册的向量插件。
+func ListEmbeddings() []EmbeddingsPlugin {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	out := make([]EmbeddingsPlugin, 0, len(embeddings))
+	for _, p := range embeddings {
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+// Package logging provides the slog handler that keeps secrets out of logs.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// minSecretLen skips values too short to redact without mangling ordinary text.
+const minSecretLen = 6
+
+// Redacted replaces secret values in log output.
+const Redacted = "[redacted]"
+
+var (
+	mu       sync.RWMutex
+	secrets  []string // longest first, so a secret containing another is replaced whole
+	replacer *strings.Replacer
+)
+
+// AddSecrets registers values (tokens, API keys) to be redacted from all logs written through
+// a RedactingHandler.
+func AddSecrets(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	changed := false
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < minSecretLen || contains(secrets, v) {
+			continue
+		}
+		secrets = append(secrets, v)
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		pairs = append(pairs, s, Redacted)
+	}
+	replacer = strings.NewReplacer(pairs...)
+}
+
+// Redact returns s with registered secrets replaced.
+func Redact(s string) string {
+	mu.RLock()
+	r := replacer
+	mu.RUnlock()
+	if r == nil {
+		return s
+	}
+	return r.Replace(s)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactingHandler wraps a handler and redacts registered secrets from messages and attribute values,
+// including errors and values formatted with fmt.
+type RedactingHandler struct {
+	inner slog.Handler
+}
+
+// NewRedactingHandler returns a handler that redacts before passing records to h.
+func NewRedactingHandler(h slog.Handler) *RedactingHandler {
+	return &RedactingHandler{inner: h}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.inner.Handle(ctx, out)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &RedactingHandler{inner: h.inner.WithAttrs(redacted)}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{inner: h.inner.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, g := range group {
+			attrs[i] = redactAttr(g)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	case slog.KindAny:
+		var s string
+		switch x := v.Any().(type) {
+		case error:
+			s = x.Error()
+		case fmt.Stringer:
+			s = x.String()
+		default:
+			s = fmt.Sprintf("%+v", x)
+		}
+		if r := Redact(s); r != s {
+			return slog.String(a.Key, r)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,260 @@
+// Package media downloads and classifies inbound attachments for the agent.
+package media
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+// Defaults for config.MediaConfig.
+const (
+	DefaultMaxFiles     = 4
+	DefaultMaxBytes     = 8 << 20
+	DefaultMaxTextChars = 20000
+)
+
+// Kind classifies an attachment for prompt building.
+type Kind string
+
+const (
+	KindImage    Kind = "image"    // sent to vision models, described otherwise
+	KindText     Kind = "text"     // inlined into the prompt
+	KindDocument Kind = "document" // PDF, sent to models that accept files, described otherwise
+	KindOther    Kind = "file"     // described only
+)
+
+// File is an inbound attachment.
+type File struct {
+	Name        string
+	ContentType string
+	Size        int64  // bytes, as reported by the channel
+	URL         string // where it was downloaded from
+	Width       int    // images only, when known
+	Height      int
+	// Data is the downloaded content; nil when the file was not read (see Skipped).
+	Data []byte
+	// Truncated is set when Data (text) was cut to MaxTextChars.
+	Truncated bool
+	// Skipped says why the content was not read ("too large", "unsupported type", a download error).
+	Skipped string
+}
+
+// Limits bound what Fetch reads.
+type Limits struct {
+	MaxFiles     int
+	MaxBytes     int64
+	MaxTextChars int
+}
+
+// LimitsFor returns cfg.Media with defaults applied.
+func LimitsFor(cfg *config.Config) Limits {
+	l := Limits{MaxFiles: DefaultMaxFiles, MaxBytes: DefaultMaxBytes, MaxTextChars: DefaultMaxTextChars}
+	if cfg == nil {
+		return l
+	}
+	m := cfg.Media
+	if m.MaxFiles > 0 {
+		l.MaxFiles = m.MaxFiles
+	}
+	if m.MaxBytes > 0 {
+		l.MaxBytes = m.MaxBytes
+	}
+	if m.MaxTextChars > 0 {
+		l.MaxTextChars = m.MaxTextChars
+	}
+	return l
+}
+
+// Kind classifies f by content type, falling back to the file extension.
+func (f *File) Kind() Kind {
+	ct := f.ContentType
+	if ct == "" {
+		ct = mime.TypeByExtension(strings.ToLower(path.Ext(f.Name)))
+	}
+	ct, _, _ = mime.ParseMediaType(ct)
+	switch {
+	case ct == "image/png", ct == "image/jpeg", ct == "image/gif", ct == "image/webp":
+		return KindImage
+	case strings.HasPrefix(ct, "text/"), textTypes[ct]:
+		return KindText
+	case ct == "application/pdf":
+		return KindDocument
+	case textExts[strings.ToLower(path.Ext(f.Name))]:
+		return KindText
+	}
+	return KindOther
+}
+
+var textTypes = map[string]bool{
+	"application/json": true, "application/xml": true, "application/yaml": true,
+	"application/x-yaml": true, "application/toml": true, "application/javascript": true,
+	"application/x-sh": true, "application/sql": true,
+}
+
+var textExts = map[string]bool{
+	".txt": true, ".md": true, ".log": true, ".csv": true, ".json": true, ".yaml": true, ".yml": true,
+	".toml": true, ".xml": true, ".go": true, ".py": true, ".js": true, ".ts": true, ".java": true,
+	".c": true, ".h": true, ".cpp": true, ".rs": true, ".rb": true, ".sh": true, ".sql": true, ".ini": true,
+}
+
+// DataURL returns Data as a base64 data: URL.
+func (f *File) DataURL() string {
+	ct, _, _ := mime.ParseMediaType(f.ContentType)
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	return "data:" + ct + ";base64," + base64.StdEncoding.EncodeToString(f.Data)
+}
+
+// Describe returns a one-line textual stand-in for f, e.g. "[image: cat.png, image/png, 800x600, 120 KB]".
+func (f *File) Describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s: %s", f.Kind(), f.Name)
+	if f.ContentType != "" {
+		b.WriteString(", " + f.ContentType)
+	}
+	if f.Width > 0 && f.Height > 0 {
+		fmt.Fprintf(&b, ", %dx%d", f.Width, f.Height)
+	}
+	if f.Size > 0 {
+		b.WriteString(", " + formatSize(f.Size))
+	}
+	if f.Skipped != "" {
+		b.WriteString(", not read: " + f.Skipped)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// Link returns a one-line stand-in for an outgoing file that could not be uploaded:
+// its URL when it has one, a note otherwise.
+func (f *File) Link() string {
+	if f.URL != "" {
+		return "📎 " + f.Name + ": " + f.URL
+	}
+	note := "📎 " + f.Name
+	if f.Size > 0 {
+		note += " (" + formatSize(f.Size) + ")"
+	}
+	return note + " could not be sent here"
+}
+
+// FallbackText appends the links of files to text, for channels that cannot take uploads.
+func FallbackText(text string, files []File) string {
+	links := make([]string, 0, len(files))
+	for i := range files {
+		links = append(links, files[i].Link())
+	}
+	if len(links) == 0 {
+		return text
+	}
+	if strings.TrimSpace(text) == "" {
+		return strings.Join(links, "\n")
+	}
+	return text + "\n\n" + strings.Join(links, "\n")
+}
+
+// Text returns Data as text for KindText files.
+func (f *File) Text() string {
+	return string(f.Data)
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Fetch downloads f.URL into f.Data when its kind can be used (images, text, PDFs) and it fits the limits;
+// otherwise it records the reason in f.Skipped. Errors are recorded the same way, so a failed
+// download degrades to a description instead of failing the message.
+func Fetch(ctx context.Context, f *File, lim Limits) {
+	kind := f.Kind()
+	switch {
+	case kind == KindOther:
+		f.Skipped = "unsupported type"
+		return
+	case lim.MaxBytes > 0 && f.Size > lim.MaxBytes:
+		f.Skipped = "too large"
+		return
+	}
+	data, err := download(ctx, f.URL, lim.MaxBytes)
+	if err != nil {
+		f.Skipped = err.Error()
+		return
+	}
+	if kind == KindText {
+		if !utf8.Valid(data) {
+			f.Skipped = "not UTF-8 text"
+			return
+		}
+		if r := []rune(string(data)); lim.MaxTextChars > 0 && len(r) > lim.MaxTextChars {
+			data, f.Truncated = []byte(string(r[:lim.MaxTextChars])), true
+		}
+	}
+	f.Data = data
+}
+
+// FetchAll fetches files in order, concurrently; files beyond lim.MaxFiles are marked skipped.
+func FetchAll(ctx context.Context, files []File, lim Limits) {
+	var wg sync.WaitGroup
+	for i := range files {
+		if lim.MaxFiles > 0 && i >= lim.MaxFiles {
+			files[i].Skipped = "attachment limit reached"
+			continue
+		}
+		wg.Add(1)
+		go func(f *File) {
+			defer wg.Done()
+			Fetch(ctx, f, lim)
+		}(&files[i])
+	}
+	wg.Wait()
+}
+
+func download(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.New("bad url")
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		slog.Warn("media: download", "err", err)
+		return nil, errors.New("download failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed (status %d)", resp.StatusCode)
+	}
+	r := io.Reader(resp.Body)
+	if maxBytes > 0 {
+		r = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.New("download failed")
+	}
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, errors.New("too large")
+	}
+	return data, nil
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters (the usual defaults).
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// rank scores entries against query with BM25 and returns those matching at least one term,
+// best first (newest first on ties).
+func rank(entries []Entry, query string) []Hit {
+	terms := tokenize(query)
+	if len(terms) == 0 || len(entries) == 0 {
+		return nil
+	}
+	docs := make([][]string, len(entries))
+	df := map[string]int{}
+	total := 0
+	for i, e := range entries {
+		docs[i] = tokenize(e.Text)
+		total += len(docs[i])
+		seen := map[string]bool{}
+		for _, t := range docs[i] {
+			if !seen[t] {
+				seen[t] = true
+				df[t]++
+			}
+		}
+	}
+	avgLen := float64(total) / float64(len(entries))
+	n := float64(len(entries))
+
+	var hits []Hit
+	for i, doc := range docs {
+		tf := map[string]int{}
+		for _, t := range doc {
+			tf[t]++
+		}
+		score := 0.0
+		for _, q := range uniq(terms) {
+			f := float64(tf[q])
+			if f == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[q])+0.5)/(float64(df[q])+0.5))
+			score += idf * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*float64(len(doc))/avgLen))
+		}
+		if score > 0 {
+			hits = append(hits, Hit{Entry: entries[i], Score: score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+	return hits
+}
+
+// tokenize lowercases s and splits it into words of letters and digits. CJK text has no spaces,
+// so each Han/kana/Hangul character is a term, plus bigrams of adjacent characters.
+func tokenize(s string) []string {
+	var out []string
+	var word []rune
+	var prevCJK rune
+	flush := func() {
+		if len(word) > 0 {
+			out = append(out, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case isCJK(r):
+			flush()
+			out = append(out, string(r))
+			if prevCJK != 0 {
+				out = append(out, string([]rune{prevCJK, r}))
+			}
+			prevCJK = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+		prevCJK = 0
+	}
+	flush()
+	return out
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func uniq(terms []string) []string {
+	seen := map[string]bool{}
+	out := terms[:0:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,285 @@
+// Package memory stores long-term facts about users, per agent and canonical user identity, in
+// local JSON files. Search is semantic (cosine similarity over embeddings) when an Embedder is set
+// and falls back to offline keyword (BM25) search otherwise.
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries caps the memories kept per agent and user; the oldest are dropped first.
+const DefaultMaxEntries = 500
+
+// Entry is one remembered fact.
+type Entry struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	// Source is the session key the fact was saved from.
+	Source string `json:"source,omitempty"`
+	// Vector is the embedding of Text under VectorModel; entries without one (or from another
+	// model) are embedded on the next semantic search.
+	Vector      []float32 `json:"vector,omitempty"`
+	VectorModel string    `json:"vector_model,omitempty"`
+}
+
+// Hit is a search result.
+type Hit struct {
+	Entry
+	Score float64
+}
+
+// Store keeps memories in <dir>/<agent>/<user>.json. It is safe for concurrent use.
+type Store struct {
+	// MaxEntries caps entries per agent and user (0 = DefaultMaxEntries).
+	MaxEntries int
+	// Embedder enables semantic search; nil means keyword search only.
+	Embedder Embedder
+	// MinScore drops semantic hits with a lower cosine similarity (0 = DefaultMinScore).
+	MinScore float64
+
+	dir string
+	mu  sync.Mutex
+}
+
+// DefaultDir returns ~/.openclaw/memory.
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	if home == "" {
+		return filepath.Join(".openclaw", "memory")
+	}
+	return filepath.Join(home, ".openclaw", "memory")
+}
+
+// Open returns a store rooted at dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save remembers text for user. Saving a fact that is already stored returns the existing entry.
+// With an Embedder the entry is stored with its vector; if embedding fails it is saved without one.
+func (s *Store) Save(ctx context.Context, agentID, user, text, source string) (Entry, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Entry{}, errors.New("memory: empty text")
+	}
+	e := Entry{ID: newID(), Text: text, CreatedAt: time.Now().UTC(), Source: source}
+	if s.Embedder != nil {
+		if vs, err := s.Embedder.Embed(ctx, []string{text}); err != nil {
+			slog.Warn("memory: embed", "agent", agentID, "err", err)
+		} else {
+			e.Vector, e.VectorModel = vs[0], s.Embedder.Model()
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.load(agentID, user)
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.Text, text) {
+			return e, nil
+		}
+	}
+	entries = append(entries, e)
+	limit := s.MaxEntries
+	if limit <= 0 {
+		limit = DefaultMaxEntries
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return e, s.store(agentID, user, entries)
+}
+
+// List returns user's memories, oldest first.
+func (s *Store) List(agentID, user string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(agentID, user)
+}
+
+// Search returns up to limit memories ranked by relevance to query: cosine similarity when an
+// Embedder is set, BM25 otherwise or when embedding fails. An empty query returns the most recent ones.
+func (s *Store) Search(ctx context.Context, agentID, user, query string, limit int) ([]Hit, error) {
+	entries, err := s.List(agentID, user)
+	if err != nil {
+		return nil, err
+	}
+	var hits []Hit
+	switch {
+	case strings.TrimSpace(query) == "":
+		for i := len(entries) - 1; i >= 0; i-- {
+			hits = append(hits, Hit{Entry: entries[i]})
+		}
+	case s.Embedder != nil && len(entries) > 0:
+		if hits, err = s.semantic(ctx, agentID, user, entries, query); err != nil {
+			slog.Warn("memory: semantic search failed, using keyword search", "agent", agentID, "err", err)
+			hits = rank(entries, query)
+		}
+	default:
+		hits = rank(entries, query)
+	}
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// Forget deletes the memory with id; it reports whether one was found.
+func (s *Store) Forget(agentID, user, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.load(agentID, user)
+	if err != nil {
+		return false, err
+	}
+	for i, e := range entries {
+		if e.ID == id {
+			return true, s.store(agentID, user, append(entries[:i], entries[i+1:]...))
+		}
+	}
+	return false, nil
+}
+
+// Purge deletes all memories of user for agentID, or for every agent when agentID is empty.
+// It returns the number of entries removed per agent, for the agents that had any.
+func (s *Store) Purge(agentID, user string) (map[string]int, error) {
+	agents := []string{agentID}
+	if agentID == "" {
+		var err error
+		if agents, err = s.Agents(); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := make(map[string]int)
+	for _, a := range agents {
+		entries, err := s.load(a, user)
+		if err != nil {
+			return purged, err
+		}
+		if err := os.Remove(s.path(a, user)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return purged, err
+		}
+		if len(entries) > 0 {
+			purged[a] = len(entries)
+		}
+	}
+	return purged, nil
+}
+
+// Agents lists the agents that have memories.
+func (s *Store) Agents() ([]string, error) {
+	des, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, de := range des {
+		if de.IsDir() {
+			if a, err := url.QueryUnescape(de.Name()); err == nil {
+				out = append(out, a)
+			}
+		}
+	}
+	return out, nil
+}
+
+// Users lists the users with memories for agentID.
+func (s *Store) Users(agentID string) ([]string, error) {
+	des, err := os.ReadDir(filepath.Join(s.dir, escape(agentID)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, de := range des {
+		name, ok := strings.CutSuffix(de.Name(), ".json")
+		if !ok || de.IsDir() {
+			continue
+		}
+		if u, err := url.QueryUnescape(name); err == nil {
+			out = append(out, u)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (s *Store) path(agentID, user string) string {
+	return filepath.Join(s.dir, escape(agentID), escape(user)+".json")
+}
+
+func (s *Store) load(agentID, user string) ([]Entry, error) {
+	data, err := os.ReadFile(s.path(agentID, user))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *Store) store(agentID, user string, entries []Entry) error {
+	p := s.path(agentID, user)
+	if len(entries) == 0 {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+// escape makes agent IDs and user identities ("discord:123") safe as file names.
+func escape(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		s = "_"
+	}
+	return url.QueryEscape(s)
+}
+
+func newID() string {
+	var b [6]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().Format("20060102150405.000000")
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"sort"
+)
+
+// DefaultMinScore is the cosine similarity below which semantic hits are dropped.
+const DefaultMinScore = 0.3
+
+// Embedder turns texts into vectors (llm.Embedder satisfies it).
+type Embedder interface {
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model identifies the vector space; stored vectors from another model are recomputed.
+	Model() string
+}
+
+// semantic ranks entries by cosine similarity to query. Entries without a current vector are embedded
+// in the same request as the query, and their vectors are written back so the next search is cheaper.
+func (s *Store) semantic(ctx context.Context, agentID, user string, entries []Entry, query string) ([]Hit, error) {
+	model := s.Embedder.Model()
+	texts := []string{query}
+	var stale []int
+	for i, e := range entries {
+		if e.VectorModel != model || len(e.Vector) == 0 {
+			stale = append(stale, i)
+			texts = append(texts, e.Text)
+		}
+	}
+	vs, err := s.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vs) != len(texts) {
+		return nil, errors.New("memory: embedder returned wrong number of vectors")
+	}
+	for n, i := range stale {
+		entries[i].Vector, entries[i].VectorModel = vs[n+1], model
+	}
+	if len(stale) > 0 {
+		if err := s.index(agentID, user, entries, model); err != nil {
+			slog.Warn("memory: store vectors", "agent", agentID, "err", err)
+		}
+	}
+
+	minScore := s.MinScore
+	if minScore <= 0 {
+		minScore = DefaultMinScore
+	}
+	var hits []Hit
+	for _, e := range entries {
+		if score := cosine(vs[0], e.Vector); score >= minScore {
+			hits = append(hits, Hit{Entry: e, Score: score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits, nil
+}
+
+// index writes the vectors of embedded into the stored entries with the same id, leaving entries
+// saved or forgotten meanwhile alone.
+func (s *Store) index(agentID, user string, embedded []Entry, model string) error {
+	byID := make(map[string][]float32, len(embedded))
+	for _, e := range embedded {
+		byID[e.ID] = e.Vector
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.load(agentID, user)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i := range entries {
+		if v, ok := byID[entries[i].ID]; ok && entries[i].VectorModel != model {
+			entries[i].Vector, entries[i].VectorModel = v, model
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.store(agentID, user, entries)
+}
+
+// cosine returns the cosine similarity of a and b, or 0 when they differ in length or are zero.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
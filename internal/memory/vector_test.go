@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeEmbedder maps words to concept dimensions, so paraphrases ("espresso", "coffee") share a
+// vector while having no words in common. It records the texts it embedded.
+type fakeEmbedder struct {
+	model string
+	err   error
+	texts []string
+}
+
+var fakeConcepts = [][]string{
+	{"coffee", "espresso", "latte"},
+	{"cat", "kitten", "cats"},
+	{"berlin", "germany"},
+	{"tea", "matcha"},
+}
+
+func (f *fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	f.texts = append(f.texts, texts...)
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, len(fakeConcepts))
+		for _, w := range strings.Fields(strings.ToLower(text)) {
+			for d, words := range fakeConcepts {
+				for _, c := range words {
+					if w == c {
+						v[d]++
+					}
+				}
+			}
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func (f *fakeEmbedder) Model() string {
+	if f.model == "" {
+		return "fake"
+	}
+	return f.model
+}
+
+func openTest(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func save(t *testing.T, s *Store, texts ...string) {
+	t.Helper()
+	for _, text := range texts {
+		if _, err := s.Save(context.Background(), "main", "alice", text, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func search(t *testing.T, s *Store, query string) []string {
+	t.Helper()
+	hits, err := s.Search(context.Background(), "main", "alice", query, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]string, len(hits))
+	for i, h := range hits {
+		out[i] = h.Text
+	}
+	return out
+}
+
+func TestSearchSemanticRanksByCosine(t *testing.T) {
+	s := openTest(t)
+	s.Embedder = &fakeEmbedder{}
+	save(t, s, "lives in berlin", "drinks espresso every morning", "has two cats", "prefers latte over espresso with a cat")
+
+	got := search(t, s, "coffee")
+	want := []string{"drinks espresso every morning", "prefers latte over espresso with a cat"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("coffee: got %q, want %q", got, want)
+	}
+	// No word in common with the query: only the vectors can find it.
+	if got := search(t, s, "germany"); len(got) != 1 || got[0] != "lives in berlin" {
+		t.Errorf("germany: got %q", got)
+	}
+	// Nothing above MinScore.
+	if got := search(t, s, "matcha"); len(got) != 0 {
+		t.Errorf("matcha: got %q, want no hits", got)
+	}
+}
+
+func TestSearchSemanticMinScore(t *testing.T) {
+	s := openTest(t)
+	s.Embedder = &fakeEmbedder{}
+	s.MinScore = 0.9
+	save(t, s, "drinks espresso", "prefers latte over espresso with a cat")
+	if got := search(t, s, "coffee"); len(got) != 1 || got[0] != "drinks espresso" {
+		t.Errorf("got %q, want only the exact concept match", got)
+	}
+}
+
+func TestSearchEmbedsEntriesSavedWithoutVectors(t *testing.T) {
+	s := openTest(t)
+	// Saved while only keyword search was configured.
+	save(t, s, "drinks espresso", "has a kitten")
+
+	f := &fakeEmbedder{}
+	s.Embedder = f
+	save(t, s, "lives in berlin")
+	if got := search(t, s, "cat"); len(got) != 1 || got[0] != "has a kitten" {
+		t.Errorf("got %q", got)
+	}
+	entries, err := s.List("main", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.VectorModel != "fake" || len(e.Vector) == 0 {
+			t.Errorf("%q not indexed after search: model %q", e.Text, e.VectorModel)
+		}
+	}
+
+	// Once indexed, only the query is embedded.
+	f.texts = nil
+	search(t, s, "coffee")
+	if len(f.texts) != 1 || f.texts[0] != "coffee" {
+		t.Errorf("embedded %q, want only the query", f.texts)
+	}
+
+	// A new model recomputes every vector.
+	f.model, f.texts = "fake-v2", nil
+	search(t, s, "coffee")
+	if len(f.texts) != 4 {
+		t.Errorf("embedded %d texts after a model change, want 4 (query + 3 entries)", len(f.texts))
+	}
+}
+
+func TestSearchFallsBackToKeywords(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		embedder Embedder
+	}{
+		{"no embedder", nil},
+		{"embedder error", &fakeEmbedder{err: errors.New("service down")}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTest(t)
+			save(t, s, "drinks espresso every morning", "lives in berlin", "espresso beans from berlin")
+			if tt.embedder != nil {
+				s.Embedder = tt.embedder
+			}
+			// BM25 finds the words themselves, not paraphrases.
+			if got := search(t, s, "coffee"); len(got) != 0 {
+				t.Errorf("coffee: got %q, want no keyword hits", got)
+			}
+			got := search(t, s, "berlin espresso")
+			if len(got) != 3 || got[0] != "espresso beans from berlin" {
+				t.Errorf("berlin espresso: got %q, want the entry with both words first", got)
+			}
+		})
+	}
+}
+
+func TestSaveWithFailingEmbedderKeepsEntry(t *testing.T) {
+	s := openTest(t)
+	s.Embedder = &fakeEmbedder{err: errors.New("service down")}
+	save(t, s, "drinks espresso")
+	entries, err := s.List("main", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].Vector) != 0 {
+		t.Errorf("got %+v, want the entry saved without a vector", entries)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		a, b []float32
+		want float64
+	}{
+		{[]float32{1, 0}, []float32{1, 0}, 1},
+		{[]float32{1, 0}, []float32{0, 1}, 0},
+		{[]float32{1, 1}, []float32{-1, -1}, -1},
+		{[]float32{3, 4}, []float32{6, 8}, 1},
+		{[]float32{1, 0}, []float32{1, 0, 0}, 0},
+		{[]float32{0, 0}, []float32{1, 0}, 0},
+		{nil, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := cosine(tt.a, tt.b); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("cosine(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
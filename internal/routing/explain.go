@@ -0,0 +1,203 @@
+package routing
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+// RouteExplanation is a resolved route together with the bindings that were considered.
+type RouteExplanation struct {
+	Route ResolvedAgentRoute
+	// Binding is the index of the winning binding in cfg.Bindings, or -1 when the default agent was used.
+	Binding int
+	// Checks has one entry per binding, in config order.
+	Checks []BindingCheck
+	// FirstMatch is set when routing.mode is first-match.
+	FirstMatch bool
+}
+
+// BindingCheck is how one binding fared against the message.
+type BindingCheck struct {
+	Index    int
+	AgentID  string
+	Priority int
+	// MatchedBy is set when the binding matches (see ResolvedAgentRoute.MatchedBy), even when an
+	// earlier binding won.
+	MatchedBy string
+	// Tier is the precedence tier of a matching binding, e.g. "guild" or "channel-wide".
+	Tier string
+	// Reason says why the binding does not match or, when it matches but lost, why the winner
+	// outranks it; empty for the winner.
+	Reason string
+}
+
+// String renders the explanation for people: the route first, then one line per binding.
+func (e RouteExplanation) String() string {
+	r := e.Route
+	var b strings.Builder
+	fmt.Fprintf(&b, "agent:        %s\n", r.AgentID)
+	fmt.Fprintf(&b, "session key:  %s\n", r.SessionKey)
+	fmt.Fprintf(&b, "main session: %s\n", r.MainSessionKey)
+	if e.FirstMatch {
+		fmt.Fprintf(&b, "mode:         %s\n", ModeFirstMatch)
+	}
+	if e.Binding >= 0 {
+		fmt.Fprintf(&b, "matched by:   %s (bindings[%d])\n", r.MatchedBy, e.Binding)
+	} else {
+		fmt.Fprintf(&b, "matched by:   %s (no binding matched)\n", r.MatchedBy)
+	}
+	if len(e.Checks) == 0 {
+		b.WriteString("bindings:     none configured\n")
+		return b.String()
+	}
+	b.WriteString("bindings:\n")
+	for _, c := range e.Checks {
+		fmt.Fprintf(&b, "  [%d] %s", c.Index, orDash(c.AgentID))
+		if c.Priority != 0 {
+			fmt.Fprintf(&b, " (priority %d)", c.Priority)
+		}
+		b.WriteString(": ")
+		switch {
+		case c.Index == e.Binding:
+			fmt.Fprintf(&b, "selected (%s, tier %s)\n", c.MatchedBy, c.Tier)
+		case c.MatchedBy != "":
+			fmt.Fprintf(&b, "matches (%s, tier %s) but bindings[%d] wins: %s\n", c.MatchedBy, c.Tier, e.Binding, c.Reason)
+		default:
+			fmt.Fprintf(&b, "rejected: %s\n", c.Reason)
+		}
+	}
+	return b.String()
+}
+
+// routeTarget is the normalized message a binding is matched against.
+type routeTarget struct {
+	channel, accountId string
+	peer, parentPeer   *RoutePeer
+	guildId, teamId    string
+	roles              []string
+	firstMatch         bool
+}
+
+// match reports how m matches the message (a MatchedBy value), or why it does not.
+func (t *routeTarget) match(m *config.BindingMatch) (matchedBy, reason string) {
+	if !matchesChannel(m, t.channel) {
+		return "", fmt.Sprintf("match.channel %q does not match %q", m.Channel, t.channel)
+	}
+	if !matchesAccountId(m.AccountID, t.accountId, t.firstMatch) {
+		if strings.TrimSpace(m.AccountID) == "" {
+			return "", fmt.Sprintf("match.account_id is empty, which in first-match mode only matches account %q (this is %q)", DefaultAccountID, t.accountId)
+		}
+		return "", fmt.Sprintf("match.account_id %q does not match account %q", m.AccountID, t.accountId)
+	}
+	if reason := t.narrow(m); reason != "" {
+		return "", reason
+	}
+
+	if t.peer != nil && matchesPeer(m, *t.peer) {
+		return "binding.peer", ""
+	}
+	if t.parentPeer != nil && matchesPeer(m, *t.parentPeer) {
+		return "binding.peer.parent", ""
+	}
+	// peer, guild_id and team_id are alternatives; roles, channel_ids and parent (checked above)
+	// count once one of them matched, or on their own.
+	guild := t.guildId != "" && matchesGuild(m, t.guildId)
+	team := t.teamId != "" && matchesTeam(m, t.teamId)
+	if guild || team || (m.Peer == nil && m.GuildID == "" && m.TeamID == "") {
+		switch {
+		case m.Parent != "":
+			return "binding.peer.parent", ""
+		case len(m.ChannelIDs) > 0:
+			return "binding.channel_id", ""
+		case guild:
+			return "binding.guild", ""
+		case team:
+			return "binding.team", ""
+		case len(m.Roles) > 0:
+			return "binding.roles", ""
+		}
+		// Account match (no peer/guild/team)
+		switch strings.TrimSpace(m.AccountID) {
+		case "":
+			if t.firstMatch {
+				return "", `no peer, guild_id or team_id and no account_id; use account_id "*" to match the whole channel`
+			}
+			return "binding.channel", ""
+		case "*":
+			return "binding.channel", ""
+		}
+		return "binding.account", ""
+	}
+	var why []string
+	if p := m.Peer; p != nil {
+		ids := p.IDs
+		if p.ID != "" {
+			ids = append([]string{p.ID}, p.IDs...)
+		}
+		want := p.Kind + ":" + strings.Join(ids, ",")
+		switch {
+		case t.peer == nil:
+			why = append(why, fmt.Sprintf("match.peer %s: message has no peer", want))
+		case t.parentPeer != nil:
+			why = append(why, fmt.Sprintf("match.peer %s does not match %s (parent %s)", want, t.peer, t.parentPeer))
+		default:
+			why = append(why, fmt.Sprintf("match.peer %s does not match %s", want, t.peer))
+		}
+	}
+	if m.GuildID != "" {
+		why = append(why, idMismatch("match.guild_id", m.GuildID, t.guildId, "message is not in a guild"))
+	}
+	if m.TeamID != "" {
+		why = append(why, idMismatch("match.team_id", m.TeamID, t.teamId, "message has no team"))
+	}
+	return "", strings.Join(why, "; ")
+}
+
+// narrow checks roles, channel_ids and parent, which must all hold when set. It returns why the
+// binding is excluded, or "".
+func (t *routeTarget) narrow(m *config.BindingMatch) string {
+	if len(m.Roles) > 0 && !slices.ContainsFunc(t.roles, func(r string) bool { return matchAnyID(m.Roles, r) }) {
+		return fmt.Sprintf("sender has none of match.roles %v", m.Roles)
+	}
+	if len(m.ChannelIDs) > 0 {
+		var ids []string
+		if t.peer != nil && t.peer.Kind != PeerDM {
+			ids = append(ids, t.peer.ID)
+		}
+		if t.parentPeer != nil {
+			ids = append(ids, t.parentPeer.ID)
+		}
+		if !slices.ContainsFunc(ids, func(id string) bool { return matchAnyID(m.ChannelIDs, id) }) {
+			if len(ids) == 0 {
+				return fmt.Sprintf("match.channel_ids %v: message is not in a channel", m.ChannelIDs)
+			}
+			return fmt.Sprintf("match.channel_ids %v does not include %s", m.ChannelIDs, strings.Join(ids, " or "))
+		}
+	}
+	if m.Parent != "" {
+		if t.peer == nil || t.peer.Kind != PeerThread || t.parentPeer == nil {
+			return fmt.Sprintf("match.parent %q: message is not in a thread", m.Parent)
+		}
+		if !matchID(m.Parent, t.parentPeer.ID) {
+			return fmt.Sprintf("match.parent %q does not match %q", m.Parent, t.parentPeer.ID)
+		}
+	}
+	return ""
+}
+
+func idMismatch(field, want, got, missing string) string {
+	if got == "" {
+		return fmt.Sprintf("%s %q: %s", field, want, missing)
+	}
+	return fmt.Sprintf("%s %q does not match %q", field, want, got)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,116 @@
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseIdentityLink splits an identity link "channel:peerId" (e.g. "discord:123") into its parts.
+func ParseIdentityLink(link string) (channel, peerID string, err error) {
+	c, id, ok := strings.Cut(strings.TrimSpace(link), ":")
+	channel, peerID = NormalizeToken(c), NormalizeID(id)
+	if !ok || channel == "" || peerID == "" {
+		return "", "", fmt.Errorf("identity link %q: want channel:peer_id", link)
+	}
+	if strings.ContainsAny(channel, " \t") || strings.ContainsAny(peerID, " \t:") {
+		return "", "", fmt.Errorf("identity link %q: channel and peer id must not contain spaces or extra colons", link)
+	}
+	return channel, peerID, nil
+}
+
+// ValidateIdentityLinks checks session.identity_links: canonical names must be valid ids, every link
+// must be "channel:peer_id", and a link may belong to only one canonical identity.
+func ValidateIdentityLinks(links map[string][]string) error {
+	names := make([]string, 0, len(links))
+	for name := range links {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	owner := make(map[string]string)
+	for _, name := range names {
+		canonical := NormalizeToken(name)
+		if !validIDRe.MatchString(canonical) {
+			return fmt.Errorf("identity_links: invalid identity name %q (use a-z, 0-9, _ or -)", name)
+		}
+		for _, link := range links[name] {
+			channel, peerID, err := ParseIdentityLink(link)
+			if err != nil {
+				return fmt.Errorf("identity_links.%s: %w", name, err)
+			}
+			k := channel + ":" + peerID
+			if prev, dup := owner[k]; dup && prev != canonical {
+				return fmt.Errorf("identity_links: %s is linked to both %q and %q", k, prev, canonical)
+			}
+			owner[k] = canonical
+		}
+	}
+	return nil
+}
+
+// ResolveLinkedPeerID returns the canonical identity for peerID on channel when it appears in
+// identity links, or peerID unchanged. Invalid links are ignored.
+func ResolveLinkedPeerID(links map[string][]string, channel, peerID string) string {
+	peerID = NormalizeID(peerID)
+	channel = NormalizeToken(channel)
+	if len(links) == 0 || peerID == "" || channel == "" {
+		return peerID
+	}
+	names := make([]string, 0, len(links))
+	for name := range links {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		canonical := NormalizeToken(name)
+		if canonical == "" {
+			continue
+		}
+		for _, link := range links[name] {
+			c, id, err := ParseIdentityLink(link)
+			if err == nil && c == channel && id == peerID {
+				return canonical
+			}
+		}
+	}
+	return peerID
+}
+
+// CanonicalIdentity returns the identity-links name for peerID on channel, or "channel:peerID" when
+// it is not linked, so the same person maps to one identity across channels.
+func CanonicalIdentity(links map[string][]string, channel, peerID string) string {
+	id := ResolveLinkedPeerID(links, channel, peerID)
+	if id != NormalizeID(peerID) || id == "" {
+		return id
+	}
+	return NormalizeToken(channel) + ":" + id
+}
+
+// IdentityAliases returns every identity data of user may be stored under: the identity-links name
+// and each of its "channel:peerId" links, so data saved before a link was added is included. user is
+// a name or a "channel:peerId" link; a user without links is returned alone.
+func IdentityAliases(links map[string][]string, user string) []string {
+	canonical := NormalizeToken(user)
+	if channel, id, ok := strings.Cut(user, ":"); ok {
+		canonical = CanonicalIdentity(links, channel, id)
+	}
+	var aliases []string
+	for name, list := range links {
+		if NormalizeToken(name) != canonical {
+			continue
+		}
+		for _, link := range list {
+			if c, id, err := ParseIdentityLink(link); err == nil {
+				aliases = append(aliases, c+":"+id)
+			}
+		}
+	}
+	sort.Strings(aliases)
+	out := []string{canonical}
+	for _, a := range aliases {
+		if a != out[len(out)-1] && a != canonical {
+			out = append(out, a)
+		}
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package routing
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// regexCache holds compiled "re:" patterns; invalid ones are stored as nil and never match.
+var regexCache sync.Map // string -> *regexp.Regexp
+
+// matchID reports whether id matches a binding pattern: an exact id, a glob (path.Match syntax) or
+// "re:<regexp>" matched against the whole id. An empty pattern or id never matches.
+func matchID(pattern, id string) bool {
+	pattern, id = NormalizeID(pattern), NormalizeID(id)
+	if pattern == "" || id == "" {
+		return false
+	}
+	if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+		re, cached := regexCache.Load(pattern)
+		if !cached {
+			compiled, err := regexp.Compile(`^(?:` + expr + `)$`)
+			if err != nil {
+				compiled = nil
+			}
+			re, _ = regexCache.LoadOrStore(pattern, compiled)
+		}
+		compiled := re.(*regexp.Regexp)
+		return compiled != nil && compiled.MatchString(id)
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		ok, _ := path.Match(pattern, id)
+		return ok
+	}
+	return pattern == id
+}
+
+// matchAnyID reports whether id matches any of patterns.
+func matchAnyID(patterns []string, id string) bool {
+	for _, p := range patterns {
+		if matchID(p, id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+// Resolution modes (routing.mode in the config).
+const (
+	// ModeTiered picks the most specific matching binding; it is the default.
+	ModeTiered = "tiered"
+	// ModeFirstMatch picks the first matching binding in file order, as older versions did, and
+	// keeps their reading of an empty account_id as "the default account only".
+	ModeFirstMatch = "first-match"
+)
+
+// tier ranks a matching binding by how specifically it matched; lower is more specific:
+//
+//	peer > thread parent > guild+channel > guild > team > account > channel-wide > default
+//
+// match.roles does not change the tier: it narrows a binding within its scope, so a guild binding
+// that requires a sender role ranks as "guild" and wins over a plain guild binding, but not over a
+// channel-specific one. Roles alone are scoped by account_id (account or channel-wide).
+//
+// In ModeTiered every binding is checked and the winner is the one with the highest priority,
+// then the most specific tier, then one that also requires a sender role, then the earliest in
+// the file. ModeFirstMatch only looks at priority and file order.
+type tier int
+
+const (
+	tierPeer         tier = iota // match.peer is the conversation
+	tierParent                   // match.peer or match.parent is the thread's parent channel
+	tierGuildChannel             // match.channel_ids contains the channel
+	tierGuild                    // match.guild_id
+	tierTeam                     // match.team_id
+	tierAccount                  // only a specific match.account_id
+	tierChannel                  // the whole channel (account_id empty or "*")
+)
+
+var tierNames = [...]string{"peer", "thread parent", "guild+channel", "guild", "team", "account", "channel-wide"}
+
+func (t tier) String() string {
+	return tierNames[t]
+}
+
+// bindingTier returns the tier of binding m, which matched as matchedBy.
+func bindingTier(m *config.BindingMatch, matchedBy string) tier {
+	switch matchedBy {
+	case "binding.peer":
+		return tierPeer
+	case "binding.peer.parent":
+		return tierParent
+	case "binding.channel_id":
+		return tierGuildChannel
+	case "binding.guild":
+		return tierGuild
+	case "binding.team":
+		return tierTeam
+	case "binding.account":
+		return tierAccount
+	}
+	// binding.roles and binding.channel are scoped by account_id alone.
+	if a := strings.TrimSpace(m.AccountID); a != "" && a != "*" {
+		return tierAccount
+	}
+	return tierChannel
+}
+
+// newCandidate ranks binding b at index i, which matched as matchedBy.
+func newCandidate(i int, b *config.AgentBinding, matchedBy string) candidate {
+	return candidate{index: i, priority: b.Priority, tier: bindingTier(&b.Match, matchedBy), roles: len(b.Match.Roles) > 0}
+}
+
+// candidate is a binding that matches the message.
+type candidate struct {
+	index    int
+	priority int
+	tier     tier
+	roles    bool // requires a sender role; breaks ties within a tier
+}
+
+// outranks reports whether c wins over o, and why.
+func (c candidate) outranks(o candidate, firstMatch bool) (bool, string) {
+	switch {
+	case c.priority != o.priority:
+		return c.priority > o.priority, "higher priority"
+	case firstMatch:
+	case c.tier != o.tier:
+		return c.tier < o.tier, fmt.Sprintf("more specific (%s beats %s)", min(c.tier, o.tier), max(c.tier, o.tier))
+	case c.roles != o.roles:
+		return c.roles, fmt.Sprintf("more specific (%s with sender roles)", c.tier)
+	}
+	return c.index < o.index, "earlier in the file"
+}
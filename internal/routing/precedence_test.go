@@ -0,0 +1,164 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+// precedenceBindings has one binding per tier that matches precedenceInput, keyed by agent id.
+var precedenceBindings = map[string]config.AgentBinding{
+	"wide":   {AgentID: "wide", Match: config.BindingMatch{Channel: "discord"}},
+	"acct":   {AgentID: "acct", Match: config.BindingMatch{Channel: "discord", AccountID: "work"}},
+	"team":   {AgentID: "team", Match: config.BindingMatch{Channel: "discord", TeamID: "t1"}},
+	"guild":  {AgentID: "guild", Match: config.BindingMatch{Channel: "discord", GuildID: "g1"}},
+	"mods":   {AgentID: "mods", Match: config.BindingMatch{Channel: "discord", Roles: []string{"r1"}}},
+	"chan":   {AgentID: "chan", Match: config.BindingMatch{Channel: "discord", GuildID: "g1", ChannelIDs: []string{"c1"}}},
+	"parent": {AgentID: "parent", Match: config.BindingMatch{Channel: "discord", Peer: &config.PeerMatch{Kind: "channel", ID: "c1"}}},
+	"peer":   {AgentID: "peer", Match: config.BindingMatch{Channel: "discord", Peer: &config.PeerMatch{Kind: "thread", ID: "th1"}}},
+
+	"acctmods":  {AgentID: "acctmods", Match: config.BindingMatch{Channel: "discord", AccountID: "work", Roles: []string{"r1"}}},
+	"guildmods": {AgentID: "guildmods", Match: config.BindingMatch{Channel: "discord", GuildID: "g1", Roles: []string{"r1"}}},
+	"other":     {AgentID: "other", Match: config.BindingMatch{Channel: "telegram"}},
+}
+
+// precedenceInput is a message in thread th1 of channel c1, guild g1, team t1, on account "work",
+// from a sender with role r1.
+func precedenceInput() ResolveAgentRouteInput {
+	return ResolveAgentRouteInput{
+		Channel:       "discord",
+		AccountID:     "work",
+		Peer:          &RoutePeer{Kind: PeerThread, ID: "th1"},
+		ParentPeer:    &RoutePeer{Kind: PeerChannel, ID: "c1"},
+		GuildID:       "g1",
+		TeamID:        "t1",
+		MemberRoleIDs: []string{"r1"},
+	}
+}
+
+func TestExplainAgentRoutePrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		// bindings are agent ids from precedenceBindings, in file order.
+		bindings []string
+		mode     string
+		// priority sets the priority of the binding at that index.
+		priority map[int]int
+		account  string
+		noRoles  bool
+		want     string
+		wantBy   string
+	}{
+		// One row per tier: each binding is listed before the more specific ones, so file order
+		// would pick the least specific.
+		{name: "peer", bindings: []string{"wide", "acct", "team", "guild", "chan", "parent", "peer"}, want: "peer", wantBy: "binding.peer"},
+		{name: "thread parent", bindings: []string{"wide", "acct", "team", "guild", "chan", "parent"}, want: "parent", wantBy: "binding.peer.parent"},
+		{name: "guild+channel", bindings: []string{"wide", "acct", "team", "guild", "chan"}, want: "chan", wantBy: "binding.channel_id"},
+		{name: "guild", bindings: []string{"wide", "acct", "team", "guild"}, want: "guild", wantBy: "binding.guild"},
+		{name: "team", bindings: []string{"wide", "acct", "team"}, want: "team", wantBy: "binding.team"},
+		{name: "account", bindings: []string{"wide", "acct"}, want: "acct", wantBy: "binding.account"},
+		{name: "channel-wide", bindings: []string{"other", "wide"}, want: "wide", wantBy: "binding.channel"},
+		{name: "default", bindings: []string{"other"}, want: DefaultAgentID, wantBy: "default"},
+		{name: "no bindings", want: DefaultAgentID, wantBy: "default"},
+		{name: "order does not matter", bindings: []string{"peer", "parent", "chan", "guild", "team", "acct", "wide"}, want: "peer", wantBy: "binding.peer"},
+
+		// Sender roles narrow a binding within its tier: they break ties, they do not lift it.
+		{name: "account beats roles", bindings: []string{"acct", "mods"}, want: "acct", wantBy: "binding.account"},
+		{name: "account roles beat account", bindings: []string{"acct", "acctmods"}, want: "acctmods", wantBy: "binding.roles"},
+		{name: "roles beat channel-wide", bindings: []string{"wide", "mods"}, want: "mods", wantBy: "binding.roles"},
+		{name: "guild beats roles", bindings: []string{"wide", "acct", "team", "guild", "mods"}, want: "guild", wantBy: "binding.guild"},
+		{name: "guild roles beat guild", bindings: []string{"wide", "acct", "team", "guild", "guildmods"}, want: "guildmods", wantBy: "binding.guild"},
+		{name: "guild roles beat roles", bindings: []string{"mods", "guildmods"}, want: "guildmods", wantBy: "binding.guild"},
+		{name: "guild+channel beats roles", bindings: []string{"mods", "chan"}, want: "chan", wantBy: "binding.channel_id"},
+		{name: "guild+channel beats guild roles", bindings: []string{"guildmods", "chan"}, want: "chan", wantBy: "binding.channel_id"},
+		{name: "roles not held", bindings: []string{"acct", "acctmods"}, noRoles: true, want: "acct", wantBy: "binding.account"},
+		{name: "priority lifts roles", bindings: []string{"guild", "mods"}, priority: map[int]int{1: 1}, want: "mods", wantBy: "binding.roles"},
+
+		// priority overrides tiers, then ties go to the earlier binding.
+		{name: "priority beats peer", bindings: []string{"peer", "wide"}, priority: map[int]int{1: 10}, want: "wide", wantBy: "binding.channel"},
+		{name: "negative priority loses", bindings: []string{"peer", "wide"}, priority: map[int]int{0: -1}, want: "wide", wantBy: "binding.channel"},
+		{name: "highest priority wins", bindings: []string{"wide", "acct", "guild"}, priority: map[int]int{0: 2, 1: 5, 2: 3}, want: "acct", wantBy: "binding.account"},
+		{name: "same tier earlier wins", bindings: []string{"guild", "guildmods2", "guild2"}, want: "guild", wantBy: "binding.guild"},
+		{name: "same priority same tier earlier wins", bindings: []string{"wide", "guild2", "guild"}, priority: map[int]int{1: 3, 2: 3}, want: "guild2", wantBy: "binding.guild"},
+
+		// first-match: priority, then file order; an empty account_id matches the default account only.
+		{name: "first-match file order", mode: ModeFirstMatch, bindings: []string{"wideall", "peer"}, want: "wideall", wantBy: "binding.channel"},
+		{name: "first-match priority", mode: ModeFirstMatch, bindings: []string{"wideall", "peer"}, priority: map[int]int{1: 1}, account: DefaultAccountID, want: "peer", wantBy: "binding.peer"},
+		{name: "first-match empty account other account", mode: ModeFirstMatch, bindings: []string{"wide", "acct"}, want: "acct", wantBy: "binding.account"},
+		{name: "first-match empty account no scope", mode: ModeFirstMatch, bindings: []string{"wide"}, account: DefaultAccountID, want: DefaultAgentID, wantBy: "default"},
+		{name: "first-match empty account default account", mode: ModeFirstMatch, bindings: []string{"guild", "peer"}, account: DefaultAccountID, want: "guild", wantBy: "binding.guild"},
+		{name: "first-match empty account skips", mode: ModeFirstMatch, bindings: []string{"guild", "peer"}, want: DefaultAgentID, wantBy: "default"},
+		{name: "tiered empty account any account", bindings: []string{"guild"}, want: "guild", wantBy: "binding.guild"},
+	}
+	extra := map[string]config.AgentBinding{
+		"guild2":     {AgentID: "guild2", Match: config.BindingMatch{Channel: "discord", GuildID: "g*"}},
+		"guildmods2": {AgentID: "guildmods2", Match: config.BindingMatch{Channel: "discord", GuildID: "g1", Roles: []string{"nobody"}}},
+		"wideall":    {AgentID: "wideall", Match: config.BindingMatch{Channel: "discord", AccountID: "*"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Routing: config.RoutingConfig{Mode: tt.mode}}
+			for i, id := range tt.bindings {
+				b, ok := precedenceBindings[id]
+				if !ok {
+					b = extra[id]
+				}
+				b.Priority = tt.priority[i]
+				cfg.Bindings = append(cfg.Bindings, b)
+			}
+			in := precedenceInput()
+			in.Cfg = cfg
+			if tt.account != "" {
+				in.AccountID = tt.account
+			}
+			if tt.noRoles {
+				in.MemberRoleIDs = nil
+			}
+			e := ExplainAgentRoute(in)
+			if e.Route.AgentID != tt.want || e.Route.MatchedBy != tt.wantBy {
+				t.Errorf("got %s (%s), want %s (%s)\n%s", e.Route.AgentID, e.Route.MatchedBy, tt.want, tt.wantBy, e)
+			}
+			if got := ResolveAgentRoute(in); got != e.Route {
+				t.Errorf("ResolveAgentRoute = %+v, ExplainAgentRoute = %+v", got, e.Route)
+			}
+		})
+	}
+}
+
+func TestExplainAgentRouteRolesReason(t *testing.T) {
+	in := precedenceInput()
+	in.Cfg = &config.Config{Bindings: []config.AgentBinding{
+		precedenceBindings["acct"], precedenceBindings["acctmods"], precedenceBindings["guildmods"], precedenceBindings["chan"],
+	}}
+	e := ExplainAgentRoute(in)
+	if e.Binding != 3 {
+		t.Fatalf("winner = bindings[%d], want bindings[3]\n%s", e.Binding, e)
+	}
+	want := []struct{ tier, reason string }{
+		{"account", "more specific (guild+channel beats account)"},
+		{"account", "more specific (guild+channel beats account)"},
+		{"guild", "more specific (guild+channel beats guild)"},
+		{"guild+channel", ""},
+	}
+	for i, w := range want {
+		if c := e.Checks[i]; c.Tier != w.tier || c.Reason != w.reason {
+			t.Errorf("bindings[%d] = %+v, want tier %q, reason %q", i, c, w.tier, w.reason)
+		}
+	}
+	if s := e.String(); !strings.Contains(s, "matches (binding.guild, tier guild)") {
+		t.Errorf("explanation does not show the guild tier of a guild+roles binding:\n%s", s)
+	}
+
+	in.Cfg.Bindings = in.Cfg.Bindings[:2]
+	e = ExplainAgentRoute(in)
+	if e.Binding != 1 {
+		t.Fatalf("winner = bindings[%d], want bindings[1]\n%s", e.Binding, e)
+	}
+	if c := e.Checks[0]; c.Reason != "more specific (account with sender roles)" {
+		t.Errorf("loser = %+v", c)
+	}
+	if s := e.String(); !strings.Contains(s, "selected (binding.roles, tier account)") {
+		t.Errorf("explanation does not show the tier:\n%s", s)
+	}
+}
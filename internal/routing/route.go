@@ -1,17 +1,21 @@
 package routing
 
 import (
-	"github.com/openclaw/openclaw-go/internal/config"
 	"strings"
+
+	"github.com/openclaw/openclaw-go/internal/config"
 )
 
-// RoutePeerKind is dm, group, or channel.
+// RoutePeerKind is dm, group, channel, or thread.
 type RoutePeerKind string
 
 const (
 	PeerDM      RoutePeerKind = "dm"
 	PeerGroup   RoutePeerKind = "group"
 	PeerChannel RoutePeerKind = "channel"
+	// PeerThread is a thread inside a channel; pair it with ParentPeer so bindings and the
+	// session key can refer to the parent channel.
+	PeerThread RoutePeerKind = "thread"
 )
 
 // RoutePeer identifies the message origin.
@@ -20,6 +24,11 @@ type RoutePeer struct {
 	ID   string
 }
 
+// String returns "kind:id".
+func (p *RoutePeer) String() string {
+	return string(p.Kind) + ":" + p.ID
+}
+
 // ResolvedAgentRoute is the result of route resolution.
 type ResolvedAgentRoute struct {
 	AgentID      string
@@ -27,7 +36,7 @@ type ResolvedAgentRoute struct {
 	AccountID    string
 	SessionKey   string
 	MainSessionKey string
-	MatchedBy    string // binding.peer, binding.guild, binding.team, binding.account, binding.channel, default
+	MatchedBy    string // binding.peer, binding.peer.parent, binding.channel_id, binding.guild, binding.team, binding.roles, binding.account, binding.channel, default
 }
 
 // ResolveAgentRouteInput for route resolution.
@@ -36,14 +45,20 @@ type ResolveAgentRouteInput struct {
 	Channel   string
 	AccountID string
 	Peer      *RoutePeer
+	// ParentPeer is the parent channel of a thread; bindings on the parent apply to its threads.
+	ParentPeer *RoutePeer
 	GuildID   string
 	TeamID    string
+	// MemberRoleIDs are the sender's role ids (Discord guild roles), for match.roles.
+	MemberRoleIDs []string
 }
 
-func matchesAccountId(matchAccountId, actual string) bool {
+// matchesAccountId: an empty match.account_id matches every account, like "*"; in first-match mode
+// it matches only the default account.
+func matchesAccountId(matchAccountId, actual string, firstMatch bool) bool {
 	m := strings.TrimSpace(matchAccountId)
 	if m == "" {
-		return actual == DefaultAccountID
+		return !firstMatch || actual == DefaultAccountID
 	}
 	if m == "*" {
 		return true
@@ -67,34 +82,24 @@ func matchesPeer(match *config.BindingMatch, peer RoutePeer) bool {
 		return false
 	}
 	m := match.Peer
-	kind := NormalizeToken(m.Kind)
-	id := NormalizeID(m.ID)
-	if kind == "" || id == "" {
+	if kind := NormalizeToken(m.Kind); kind == "" || kind != string(peer.Kind) {
 		return false
 	}
-	return kind == string(peer.Kind) && id == peer.ID
+	return matchID(m.ID, peer.ID) || matchAnyID(m.IDs, peer.ID)
 }
 
 func matchesGuild(match *config.BindingMatch, guildId string) bool {
 	if match == nil {
 		return false
 	}
-	id := NormalizeID(match.GuildID)
-	if id == "" {
-		return false
-	}
-	return id == guildId
+	return matchID(match.GuildID, guildId)
 }
 
 func matchesTeam(match *config.BindingMatch, teamId string) bool {
 	if match == nil {
 		return false
 	}
-	id := NormalizeID(match.TeamID)
-	if id == "" {
-		return false
-	}
-	return id == teamId
+	return matchID(match.TeamID, teamId)
 }
 
 func resolveDefaultAgentId(cfg *config.Config) string {
@@ -125,6 +130,11 @@ func pickFirstExistingAgentId(cfg *config.Config, agentId string) string {
 
 // ResolveAgentRoute resolves which agent handles the message.
 func ResolveAgentRoute(input ResolveAgentRouteInput) ResolvedAgentRoute {
+	return ExplainAgentRoute(input).Route
+}
+
+// ExplainAgentRoute resolves the route like ResolveAgentRoute and records how each binding fared.
+func ExplainAgentRoute(input ResolveAgentRouteInput) RouteExplanation {
 	if input.Cfg == nil {
 		input.Cfg = &config.Config{}
 	}
@@ -139,6 +149,14 @@ func ResolveAgentRoute(input ResolveAgentRouteInput) ResolvedAgentRoute {
 		}
 	}
 
+	var parentPeer *RoutePeer
+	if input.ParentPeer != nil && NormalizeID(input.ParentPeer.ID) != "" {
+		parentPeer = &RoutePeer{
+			Kind: input.ParentPeer.Kind,
+			ID:   NormalizeID(input.ParentPeer.ID),
+		}
+	}
+
 	guildId := NormalizeID(input.GuildID)
 	teamId := NormalizeID(input.TeamID)
 
@@ -148,13 +166,22 @@ func ResolveAgentRoute(input ResolveAgentRouteInput) ResolvedAgentRoute {
 		if input.Cfg != nil && input.Cfg.Session.DMScope != "" {
 			dmScope = input.Cfg.Session.DMScope
 		}
+		// Threads with a known parent are scoped under the parent's key: {parentKey}:thread:{threadId}.
+		keyPeer := peer
+		if peer != nil && peer.Kind == PeerThread && parentPeer != nil {
+			keyPeer = parentPeer
+		}
 		sessionKey := BuildAgentSessionKey(BuildAgentSessionKeyParams{
-			AgentID:   resolved,
-			Channel:   channel,
-			AccountID: accountId,
-			Peer:      peer,
-			DMScope:   dmScope,
+			AgentID:       resolved,
+			Channel:       channel,
+			AccountID:     accountId,
+			Peer:          keyPeer,
+			DMScope:       dmScope,
+			IdentityLinks: input.Cfg.Session.IdentityLinks,
 		})
+		if keyPeer != peer {
+			sessionKey = BuildThreadSessionKey(sessionKey, peer.ID)
+		}
 		mainKey := BuildAgentMainSessionKey(resolved, DefaultMainKey)
 		return ResolvedAgentRoute{
 			AgentID:        resolved,
@@ -166,41 +193,47 @@ func ResolveAgentRoute(input ResolveAgentRouteInput) ResolvedAgentRoute {
 		}
 	}
 
-	for _, b := range input.Cfg.Bindings {
-		if !matchesChannel(&b.Match, channel) {
-			continue
-		}
-		if !matchesAccountId(b.Match.AccountID, accountId) {
+	// Every binding is checked, so the explanation can also say which ones the winner shadows.
+	firstMatch := input.Cfg.Routing.Mode == ModeFirstMatch
+	target := &routeTarget{
+		channel:    channel,
+		accountId:  accountId,
+		peer:       peer,
+		parentPeer: parentPeer,
+		guildId:    guildId,
+		teamId:     teamId,
+		roles:      input.MemberRoleIDs,
+		firstMatch: firstMatch,
+	}
+	out := RouteExplanation{Binding: -1, FirstMatch: firstMatch}
+	var best candidate
+	var matched []candidate
+	for i, b := range input.Cfg.Bindings {
+		matchedBy, reason := target.match(&b.Match)
+		out.Checks = append(out.Checks, BindingCheck{Index: i, AgentID: b.AgentID, Priority: b.Priority, MatchedBy: matchedBy, Reason: reason})
+		if matchedBy == "" {
 			continue
 		}
-
-		if peer != nil {
-			if matchesPeer(&b.Match, *peer) {
-				return choose(b.AgentID, "binding.peer")
-			}
-		}
-		if guildId != "" {
-			if matchesGuild(&b.Match, guildId) {
-				return choose(b.AgentID, "binding.guild")
-			}
+		c := newCandidate(i, &b, matchedBy)
+		out.Checks[i].Tier = c.tier.String()
+		matched = append(matched, c)
+		if win, _ := c.outranks(best, firstMatch); out.Binding < 0 || win {
+			best, out.Binding = c, i
 		}
-		if teamId != "" {
-			if matchesTeam(&b.Match, teamId) {
-				return choose(b.AgentID, "binding.team")
-			}
-		}
-
-		// Account match (no peer/guild/team)
-		m := b.Match
-		if m.AccountID != "" && m.AccountID != "*" && m.Peer == nil && m.GuildID == "" && m.TeamID == "" {
-			return choose(b.AgentID, "binding.account")
-		}
-		if m.AccountID == "*" && m.Peer == nil && m.GuildID == "" && m.TeamID == "" {
-			return choose(b.AgentID, "binding.channel")
+	}
+	for _, c := range matched {
+		if c.index != best.index {
+			_, out.Checks[c.index].Reason = best.outranks(c, firstMatch)
 		}
 	}
-
-	return choose(resolveDefaultAgentId(input.Cfg), "default")
+	if out.Binding >= 0 {
+		c := out.Checks[out.Binding]
+		out.Route = choose(c.AgentID, c.MatchedBy)
+	}
+	if out.Binding < 0 {
+		out.Route = choose(resolveDefaultAgentId(input.Cfg), "default")
+	}
+	return out
 }
 
 // BuildAgentSessionKeyParams for building session key.
@@ -210,6 +243,8 @@ type BuildAgentSessionKeyParams struct {
 	AccountID string
 	Peer      *RoutePeer
 	DMScope   string
+	// IdentityLinks maps canonical identities to "channel:peerId" links (session.identity_links).
+	IdentityLinks map[string][]string
 }
 
 // BuildAgentSessionKey builds the full session key.
@@ -225,11 +260,12 @@ func BuildAgentSessionKey(p BuildAgentSessionKeyParams) string {
 		peerId = "unknown"
 	}
 	return BuildAgentPeerSessionKey(PeerSessionKeyParams{
-		AgentID:   p.AgentID,
-		Channel:   p.Channel,
-		AccountID: p.AccountID,
-		PeerKind:  peerKind,
-		PeerID:    peerId,
-		DMScope:   p.DMScope,
+		AgentID:       p.AgentID,
+		Channel:       p.Channel,
+		AccountID:     p.AccountID,
+		PeerKind:      peerKind,
+		PeerID:        peerId,
+		DMScope:       p.DMScope,
+		IdentityLinks: p.IdentityLinks,
 	})
 }
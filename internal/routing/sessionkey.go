@@ -103,31 +103,68 @@ func BuildAgentPeerSessionKey(p PeerSessionKeyParams) string {
 	}
 
 	peerId := strings.TrimSpace(p.PeerID)
-	if dmScope == "main" {
+	if peerId == "" {
+		peerId = "unknown"
+	}
+	// Group DMs always get their own session, even under dm_scope main.
+	if dmScope == "main" && peerKind != "group" {
 		return BuildAgentMainSessionKey(agentId, DefaultMainKey)
 	}
+	if peerKind == "dm" {
+		// identity_links: the same person on different channels shares one DM session.
+		peerId = ResolveLinkedPeerID(p.IdentityLinks, channel, peerId)
+	}
 
-	// per-peer or per-channel-peer
+	// per-peer: agent:{id}:{kind}:{peer}
+	// per-channel-peer, per-account-channel-peer: agent:{id}:{channel}:{account}:{kind}:{peer}
+	// group under main or per-peer: agent:{id}:{channel}:group:{peer}
 	parts := []string{"agent", agentId}
-	if dmScope == "per-channel-peer" || dmScope == "per-account-channel-peer" {
+	switch {
+	case dmScope == "per-channel-peer" || dmScope == "per-account-channel-peer":
 		parts = append(parts, channel, accountId)
+	case peerKind == "group":
+		parts = append(parts, channel)
 	}
-	parts = append(parts, peerKind)
-	if peerId != "" {
-		parts = append(parts, peerId)
-	} else {
-		parts = append(parts, "unknown")
-	}
+	parts = append(parts, peerKind, peerId)
 	return strings.ToLower(strings.Join(parts, ":"))
 }
 
+// BuildThreadSessionKey scopes a thread under its parent conversation: "{baseKey}:thread:{threadId}".
+func BuildThreadSessionKey(baseKey, threadID string) string {
+	threadID = strings.TrimSpace(threadID)
+	if threadID == "" {
+		return baseKey
+	}
+	return strings.ToLower(baseKey + ":thread:" + threadID)
+}
+
+// IsThreadSessionKey reports whether key belongs to the given thread, sub-agent sessions included.
+func IsThreadSessionKey(key, threadID string) bool {
+	threadID = strings.TrimSpace(threadID)
+	key = strings.ToLower(key)
+	if i := strings.Index(key, subAgentMarker); i >= 0 {
+		key = key[:i]
+	}
+	return threadID != "" && strings.HasSuffix(key, ":thread:"+strings.ToLower(threadID))
+}
+
+const subAgentMarker = ":sub:"
+
+// BuildSubAgentSessionKey returns the session another agent uses when consulted from the
+// conversation parentKey: "{parentKey}:sub:{agentId}". Nested consultations nest the suffix.
+func BuildSubAgentSessionKey(parentKey, agentID string) string {
+	return strings.ToLower(parentKey + subAgentMarker + NormalizeAgentId(agentID))
+}
+
 // PeerSessionKeyParams for BuildAgentPeerSessionKey.
 type PeerSessionKeyParams struct {
 	AgentID    string
 	Channel    string
 	AccountID  string
-	PeerKind   string // dm, group, channel
+	PeerKind   string // dm, group, channel, thread
 	PeerID     string
 	DMScope    string
 	MainKey    string
+	// IdentityLinks maps canonical identities to "channel:peerId" links (session.identity_links).
+	IdentityLinks map[string][]string
 }
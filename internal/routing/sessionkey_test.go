@@ -0,0 +1,173 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildAgentPeerSessionKeyDMScopes(t *testing.T) {
+	links := map[string][]string{"alice": {"discord:123", "telegram:456"}}
+	tests := []struct {
+		name    string
+		scope   string
+		channel string
+		account string
+		peer    string
+		links   map[string][]string
+		want    string
+	}{
+		{"main", "main", "discord", "", "123", nil, "agent:main:main"},
+		{"empty scope is main", "", "discord", "", "123", nil, "agent:main:main"},
+		{"main ignores links", "main", "discord", "", "123", links, "agent:main:main"},
+
+		{"per-peer", "per-peer", "discord", "", "123", nil, "agent:main:dm:123"},
+		{"per-peer linked", "per-peer", "discord", "", "123", links, "agent:main:dm:alice"},
+		{"per-peer linked other channel", "per-peer", "telegram", "bot2", "456", links, "agent:main:dm:alice"},
+		{"per-peer unlinked peer", "per-peer", "discord", "", "999", links, "agent:main:dm:999"},
+		{"per-peer link is channel specific", "per-peer", "telegram", "", "123", links, "agent:main:dm:123"},
+
+		{"per-channel-peer", "per-channel-peer", "discord", "", "123", nil, "agent:main:discord:default:dm:123"},
+		{"per-channel-peer account", "per-channel-peer", "discord", "Work", "123", nil, "agent:main:discord:work:dm:123"},
+		{"per-channel-peer linked", "per-channel-peer", "telegram", "", "456", links, "agent:main:telegram:default:dm:alice"},
+
+		{"per-account-channel-peer", "per-account-channel-peer", "discord", "", "123", nil, "agent:main:discord:default:dm:123"},
+		{"per-account-channel-peer account", "per-account-channel-peer", "discord", "work", "123", nil, "agent:main:discord:work:dm:123"},
+		{"per-account-channel-peer linked", "per-account-channel-peer", "discord", "work", "123", links, "agent:main:discord:work:dm:alice"},
+
+		{"missing peer", "per-peer", "discord", "", " ", nil, "agent:main:dm:unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildAgentPeerSessionKey(PeerSessionKeyParams{
+				AgentID:       "main",
+				Channel:       tt.channel,
+				AccountID:     tt.account,
+				PeerKind:      "dm",
+				PeerID:        tt.peer,
+				DMScope:       tt.scope,
+				IdentityLinks: tt.links,
+			})
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildAgentPeerSessionKeyPeerKinds(t *testing.T) {
+	links := map[string][]string{"alice": {"discord:777"}}
+	tests := []struct {
+		scope string
+		kind  string
+		// want is the key for account "work"; wantOther for account "home" in the same conversation.
+		want, wantOther string
+	}{
+		{"main", "channel", "agent:main:main", "agent:main:main"},
+		{"main", "thread", "agent:main:main", "agent:main:main"},
+		{"main", "group", "agent:main:discord:group:777", "agent:main:discord:group:777"},
+		{"per-peer", "channel", "agent:main:channel:777", "agent:main:channel:777"},
+		{"per-peer", "thread", "agent:main:thread:777", "agent:main:thread:777"},
+		{"per-peer", "group", "agent:main:discord:group:777", "agent:main:discord:group:777"},
+		{"per-channel-peer", "channel", "agent:main:discord:work:channel:777", "agent:main:discord:home:channel:777"},
+		{"per-channel-peer", "group", "agent:main:discord:work:group:777", "agent:main:discord:home:group:777"},
+		{"per-account-channel-peer", "channel", "agent:main:discord:work:channel:777", "agent:main:discord:home:channel:777"},
+		{"per-account-channel-peer", "thread", "agent:main:discord:work:thread:777", "agent:main:discord:home:thread:777"},
+		{"per-account-channel-peer", "group", "agent:main:discord:work:group:777", "agent:main:discord:home:group:777"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope+"/"+tt.kind, func(t *testing.T) {
+			for account, want := range map[string]string{"work": tt.want, "home": tt.wantOther} {
+				got := BuildAgentPeerSessionKey(PeerSessionKeyParams{
+					AgentID:   "Main",
+					Channel:   "discord",
+					AccountID: account,
+					PeerKind:  tt.kind,
+					PeerID:    "777",
+					DMScope:   tt.scope,
+					// identity_links only apply to direct messages.
+					IdentityLinks: links,
+				})
+				if got != want {
+					t.Errorf("account %s: got %q, want %q", account, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseIdentityLink(t *testing.T) {
+	tests := []struct {
+		link          string
+		channel, peer string
+		wantErr       bool
+	}{
+		{link: "discord:123", channel: "discord", peer: "123"},
+		{link: " Discord:123 ", channel: "discord", peer: "123"},
+		{link: "discord", wantErr: true},
+		{link: ":123", wantErr: true},
+		{link: "discord:", wantErr: true},
+		{link: "", wantErr: true},
+		{link: "discord:12:3", wantErr: true},
+		{link: "disc ord:123", wantErr: true},
+		{link: "discord:1 23", wantErr: true},
+	}
+	for _, tt := range tests {
+		channel, peer, err := ParseIdentityLink(tt.link)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: want error, got %q %q", tt.link, channel, peer)
+			}
+			continue
+		}
+		if err != nil || channel != tt.channel || peer != tt.peer {
+			t.Errorf("%q: got %q %q %v, want %q %q", tt.link, channel, peer, err, tt.channel, tt.peer)
+		}
+	}
+}
+
+func TestValidateIdentityLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		links   map[string][]string
+		wantErr string
+	}{
+		{"valid", map[string][]string{"alice": {"discord:1", "telegram:2"}, "bob": {"discord:3"}}, ""},
+		{"empty", nil, ""},
+		{"bad name", map[string][]string{"Al ice": {"discord:1"}}, "invalid identity name"},
+		{"bad link", map[string][]string{"alice": {"discord"}}, "identity_links.alice"},
+		{"extra colon", map[string][]string{"alice": {"discord:1:2"}}, "extra colons"},
+		{"linked twice", map[string][]string{"alice": {"discord:1"}, "bob": {"Discord:1"}}, "linked to both"},
+		{"repeated in one identity", map[string][]string{"alice": {"discord:1", "discord:1"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIdentityLinks(tt.links)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIdentityAliases(t *testing.T) {
+	links := map[string][]string{"alice": {"telegram:456", "Discord:123"}, "bob": {"discord:789"}}
+	tests := []struct {
+		user string
+		want string
+	}{
+		{"alice", "alice discord:123 telegram:456"},
+		{"Alice", "alice discord:123 telegram:456"},
+		{"discord:123", "alice discord:123 telegram:456"},
+		{"discord:789", "bob discord:789"},
+		{"discord:999", "discord:999"},
+		{"carol", "carol"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(IdentityAliases(links, tt.user), " "); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.user, got, tt.want)
+		}
+	}
+}
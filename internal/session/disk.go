@@ -0,0 +1,225 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/llm"
+)
+
+const (
+	indexFile        = "sessions.json"
+	archiveDir       = "archive"
+	archiveIndexFile = "index.jsonl"
+)
+
+// TranscriptLine is one message in a session transcript file (<session_id>.jsonl).
+type TranscriptLine struct {
+	Role    string    `json:"role"`
+	Content string    `json:"content"`
+	Time    time.Time `json:"ts"`
+}
+
+// ArchivedSession describes a conversation that was rolled over; its transcript stays readable.
+type ArchivedSession struct {
+	SessionKey string      `json:"session_key"`
+	SessionID  string      `json:"session_id"`
+	Reason     ResetReason `json:"reason"`
+	Usage      Usage       `json:"usage"`
+	CreatedAt  time.Time   `json:"created_at"`
+	ArchivedAt time.Time   `json:"archived_at"`
+}
+
+// Open returns a store persisted under dir: sessions.json holds entry metadata, <session_id>.jsonl
+// the live transcripts and archive/ the transcripts of reset sessions.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, archiveDir), 0o700); err != nil {
+		return nil, fmt.Errorf("session: create store dir: %w", err)
+	}
+	s := NewStore()
+	s.disk = &diskStore{dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("session: read index: %w", err)
+	}
+	if len(data) > 0 {
+		var entries map[string]*Entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("session: parse %s: %w", indexFile, err)
+		}
+		for k, e := range entries {
+			if e != nil && e.SessionID != "" {
+				s.entries[normalizeKey(k)] = e
+			}
+		}
+	}
+	return s, nil
+}
+
+// DefaultDir returns ~/.openclaw/sessions.
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	if home == "" {
+		return filepath.Join(".openclaw", "sessions")
+	}
+	return filepath.Join(home, ".openclaw", "sessions")
+}
+
+// Archived lists archived conversations for key, oldest first. It returns nil for in-memory stores.
+func (s *Store) Archived(key string) ([]ArchivedSession, error) {
+	if s.disk == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.disk.readArchiveIndex()
+	if err != nil {
+		return nil, err
+	}
+	key = normalizeKey(key)
+	var out []ArchivedSession
+	for _, a := range all {
+		if a.SessionKey == key {
+			out = append(out, a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ArchivedAt.Before(out[j].ArchivedAt) })
+	return out, nil
+}
+
+// Transcript returns the full transcript of a live or archived session.
+func (s *Store) Transcript(sessionID string) ([]TranscriptLine, error) {
+	if s.disk == nil {
+		return nil, errors.New("session: transcripts require a persistent store")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines, err := readTranscript(s.disk.transcriptPath(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		lines, err = readTranscript(s.disk.archivedPath(sessionID))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("session: no transcript for %s", sessionID)
+	}
+	return lines, err
+}
+
+type diskStore struct {
+	dir string
+}
+
+func (d *diskStore) transcriptPath(sessionID string) string {
+	return filepath.Join(d.dir, filepath.Base(sessionID)+".jsonl")
+}
+
+func (d *diskStore) archivedPath(sessionID string) string {
+	return filepath.Join(d.dir, archiveDir, filepath.Base(sessionID)+".jsonl")
+}
+
+func (d *diskStore) saveIndex(entries map[string]*Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(d.dir, indexFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(d.dir, indexFile))
+}
+
+func (d *diskStore) appendTranscript(sessionID string, ts time.Time, msgs []llm.Message) error {
+	f, err := os.OpenFile(d.transcriptPath(sessionID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, m := range msgs {
+		if err := enc.Encode(TranscriptLine{Role: m.Role, Content: m.Content, Time: ts}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *diskStore) readHistory(sessionID string, limit int) ([]llm.Message, error) {
+	lines, err := readTranscript(d.transcriptPath(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	out := make([]llm.Message, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, llm.Message{Role: l.Role, Content: l.Content})
+	}
+	return out, err
+}
+
+func (d *diskStore) archive(e Entry, reason ResetReason, now time.Time) error {
+	src := d.transcriptPath(e.SessionID)
+	if err := os.Rename(src, d.archivedPath(e.SessionID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(d.dir, archiveDir, archiveIndexFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(ArchivedSession{
+		SessionKey: e.SessionKey,
+		SessionID:  e.SessionID,
+		Reason:     reason,
+		Usage:      e.Usage,
+		CreatedAt:  e.CreatedAt,
+		ArchivedAt: now,
+	})
+}
+
+func (d *diskStore) readArchiveIndex() ([]ArchivedSession, error) {
+	f, err := os.Open(filepath.Join(d.dir, archiveDir, archiveIndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []ArchivedSession
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var a ArchivedSession
+		if err := json.Unmarshal(sc.Bytes(), &a); err != nil {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, sc.Err()
+}
+
+func readTranscript(path string) ([]TranscriptLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []TranscriptLine
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var l TranscriptLine
+		if err := json.Unmarshal(sc.Bytes(), &l); err != nil {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out, sc.Err()
+}
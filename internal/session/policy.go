@@ -0,0 +1,107 @@
+package session
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+// Policy decides when a session expires and a new conversation begins.
+type Policy struct {
+	// IdleTimeout rolls the session over after this long without activity (0 = never).
+	IdleTimeout time.Duration
+	// Daily enables a rollover every day at DailyAt (offset from midnight) in Location.
+	Daily    bool
+	DailyAt  time.Duration
+	Location *time.Location
+}
+
+// Expired returns why a session last active at lastActive has expired at now, or "" if it is fresh.
+func (p Policy) Expired(lastActive, now time.Time) ResetReason {
+	if p.Daily {
+		loc := p.Location
+		if loc == nil {
+			loc = time.Local
+		}
+		t := now.In(loc)
+		boundary := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(p.DailyAt)
+		if boundary.After(t) {
+			boundary = boundary.AddDate(0, 0, -1)
+		}
+		if lastActive.Before(boundary) {
+			return ResetDaily
+		}
+	}
+	if p.IdleTimeout > 0 && now.Sub(lastActive) > p.IdleTimeout {
+		return ResetIdle
+	}
+	return ""
+}
+
+// PolicyFor builds the reset policy for a chat type ("direct", "group", "channel", "thread"):
+// session.reset is the base and session.reset_by_chat_type[chatType] overrides the fields it sets,
+// so an explicit idle_minutes: 0 there turns off a global idle timeout.
+func PolicyFor(cfg *config.Config, chatType string) (Policy, error) {
+	if cfg == nil {
+		return Policy{}, nil
+	}
+	rc := cfg.Session.Reset
+	if o, ok := cfg.Session.ResetByChatType[strings.ToLower(chatType)]; ok {
+		if o.IdleMinutes != nil {
+			rc.IdleMinutes = o.IdleMinutes
+		}
+		if o.DailyAt != "" {
+			rc.DailyAt = o.DailyAt
+		}
+		if o.Timezone != "" {
+			rc.Timezone = o.Timezone
+		}
+	}
+	return ParsePolicy(rc)
+}
+
+// ParsePolicy converts a reset config into a Policy. idle_minutes unset or <= 0 and daily_at "" or "off" disable the respective rule.
+func ParsePolicy(rc config.SessionResetConfig) (Policy, error) {
+	var p Policy
+	if rc.IdleMinutes != nil && *rc.IdleMinutes > 0 {
+		p.IdleTimeout = time.Duration(*rc.IdleMinutes) * time.Minute
+	}
+	at := strings.TrimSpace(rc.DailyAt)
+	if at == "" || strings.EqualFold(at, "off") {
+		return p, nil
+	}
+	offset, err := parseClock(at)
+	if err != nil {
+		return p, fmt.Errorf("session: daily_at %q: %w", at, err)
+	}
+	p.Daily, p.DailyAt = true, offset
+	p.Location = time.Local
+	if tz := strings.TrimSpace(rc.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return p, fmt.Errorf("session: timezone %q: %w", tz, err)
+		}
+		p.Location = loc
+	}
+	return p, nil
+}
+
+// parseClock parses "HH:MM" (24h) into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, fmt.Errorf("want HH:MM")
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid hour")
+	}
+	m, err := strconv.Atoi(mm)
+	if err != nil || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid minute")
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+func TestPolicyForIdleOverride(t *testing.T) {
+	minutes := func(n int) *int { return &n }
+	tests := []struct {
+		name     string
+		base     *int
+		override *int
+		want     time.Duration
+	}{
+		{"base only", minutes(240), nil, 240 * time.Minute},
+		{"override", minutes(240), minutes(60), time.Hour},
+		{"zero turns off", minutes(240), minutes(0), 0},
+		{"negative turns off", minutes(240), minutes(-1), 0},
+		{"override without base", nil, minutes(30), 30 * time.Minute},
+		{"unset", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Session.Reset.IdleMinutes = tt.base
+			cfg.Session.ResetByChatType = map[string]config.SessionResetConfig{
+				"direct": {IdleMinutes: tt.override},
+			}
+			p, err := PolicyFor(cfg, "direct")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.IdleTimeout != tt.want {
+				t.Errorf("direct idle = %v, want %v", p.IdleTimeout, tt.want)
+			}
+			if p, _ := PolicyFor(cfg, "channel"); tt.base != nil && p.IdleTimeout != time.Duration(*tt.base)*time.Minute {
+				t.Errorf("channel idle = %v, want the base", p.IdleTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/llm"
+)
+
+// DefaultHistoryLimit is the number of messages kept per session when Store.HistoryLimit is zero.
+const DefaultHistoryLimit = 40
+
+// subSessionMarker separates a conversation's key from the sessions of agents it consulted
+// (see routing.BuildSubAgentSessionKey); those are reset along with the conversation.
+const subSessionMarker = ":sub:"
+
+// ResetReason says why a session was rolled over to a new SessionID.
+type ResetReason string
+
+const (
+	ResetManual      ResetReason = "manual"
+	ResetAgentSwitch ResetReason = "agent-switch"
+	ResetIdle        ResetReason = "idle"
+	ResetDaily       ResetReason = "daily"
+	// ResetThreadArchived is used when the chat thread backing a session is archived.
+	ResetThreadArchived ResetReason = "thread-archived"
+)
+
+// Entry is the state kept for one session key (conversation).
+type Entry struct {
+	SessionKey string `json:"session_key"`
+	// SessionID identifies the current conversation; it changes on every reset.
+	SessionID string `json:"session_id"`
+	// AgentOverride is set by /agent and replaces the routed agent for this session.
+	AgentOverride string `json:"agent_override,omitempty"`
+	// ModelOverride is set by /model and replaces the default model for this session.
+	ModelOverride string        `json:"model_override,omitempty"`
+	History       []llm.Message `json:"-"`
+	Usage         Usage         `json:"usage"`
+	CreatedAt     time.Time     `json:"created_at"`
+	// UpdatedAt changes on every write, overrides included.
+	UpdatedAt time.Time `json:"updated_at"`
+	// LastActiveAt is the time of the last conversation turn; the idle and daily policies use it,
+	// so /model, /agent or a handoff do not keep a session alive.
+	LastActiveAt time.Time `json:"last_active_at,omitempty"`
+
+	historyLoaded bool
+}
+
+// Usage accumulates token usage for a session.
+type Usage struct {
+	Turns        int `json:"turns"`
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Store holds sessions keyed by session key. A store created by Open also persists entries and
+// transcripts to disk; NewStore keeps everything in memory.
+type Store struct {
+	// HistoryLimit caps the messages kept per session (0 = DefaultHistoryLimit).
+	HistoryLimit int
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+	disk    *diskStore
+	now     func() time.Time
+}
+
+// NewStore returns an empty in-memory store.
+func NewStore() *Store {
+	return &Store{entries: make(map[string]*Entry), now: time.Now}
+}
+
+// Get returns a snapshot of the session, creating it if needed.
+func (s *Store) Get(key string) Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entryLocked(key).snapshot()
+}
+
+// Resolve returns the session like Get, first rolling it over when policy says it has expired.
+// The returned reason is empty when the session was still fresh.
+func (s *Store) Resolve(key string, policy Policy) (Entry, ResetReason) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entryLocked(key)
+	if e.Usage.Turns == 0 {
+		return e.snapshot(), ""
+	}
+	reason := policy.Expired(e.lastActive(), s.now())
+	if reason != "" {
+		s.resetLocked(e, reason, false)
+	}
+	return e.snapshot(), reason
+}
+
+// Update applies fn to the session under lock and returns the updated snapshot.
+func (s *Store) Update(key string, fn func(e *Entry)) Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entryLocked(key)
+	fn(e)
+	e.UpdatedAt = s.now()
+	s.saveLocked()
+	return e.snapshot()
+}
+
+// Append records one completed turn: the messages are added to history and the transcript, and usage is accumulated.
+func (s *Store) Append(key string, usage llm.Usage, msgs ...llm.Message) Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entryLocked(key)
+	e.History = append(e.History, msgs...)
+	if limit := s.historyLimit(); len(e.History) > limit {
+		e.History = append([]llm.Message(nil), e.History[len(e.History)-limit:]...)
+	}
+	e.Usage.Turns++
+	e.Usage.InputTokens += usage.PromptTokens
+	e.Usage.OutputTokens += usage.CompletionTokens
+	e.UpdatedAt = s.now()
+	e.LastActiveAt = e.UpdatedAt
+	if s.disk != nil {
+		if err := s.disk.appendTranscript(e.SessionID, e.UpdatedAt, msgs); err != nil {
+			slog.Warn("session: append transcript", "session", e.SessionID, "err", err)
+		}
+	}
+	s.saveLocked()
+	return e.snapshot()
+}
+
+// Reset starts a new conversation for key: the current transcript is archived, history and usage are
+// cleared and a new SessionID is assigned. Overrides are kept unless clearOverrides is true.
+// Sessions of agents consulted from key ("{key}:sub:{agent}") are reset too, as is every
+// rollover by Resolve.
+func (s *Store) Reset(key string, reason ResetReason, clearOverrides bool) Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entryLocked(key)
+	s.resetLocked(e, reason, clearOverrides)
+	return e.snapshot()
+}
+
+// Keys returns the keys of all known sessions.
+func (s *Store) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.entries))
+	for k := range s.entries {
+		out = append(out, k)
+	}
+	return out
+}
+
+func (s *Store) resetLocked(e *Entry, reason ResetReason, clearOverrides bool) {
+	s.resetEntryLocked(e, reason, clearOverrides)
+	// Nested consultations share the prefix, so one pass covers them.
+	prefix := e.SessionKey + subSessionMarker
+	for k, sub := range s.entries {
+		if strings.HasPrefix(k, prefix) && sub.Usage.Turns > 0 {
+			s.resetEntryLocked(sub, reason, clearOverrides)
+		}
+	}
+	s.saveLocked()
+}
+
+func (s *Store) resetEntryLocked(e *Entry, reason ResetReason, clearOverrides bool) {
+	now := s.now()
+	if s.disk != nil && e.Usage.Turns > 0 {
+		if err := s.disk.archive(*e, reason, now); err != nil {
+			slog.Warn("session: archive", "session", e.SessionID, "err", err)
+		}
+	}
+	slog.Info("session: reset", "sessionKey", e.SessionKey, "previous", e.SessionID, "reason", reason)
+	e.SessionID = newSessionID()
+	e.History = nil
+	e.Usage = Usage{}
+	e.CreatedAt = now
+	e.UpdatedAt = now
+	e.LastActiveAt = time.Time{}
+	if clearOverrides {
+		e.AgentOverride = ""
+		e.ModelOverride = ""
+	}
+}
+
+func (s *Store) entryLocked(key string) *Entry {
+	key = normalizeKey(key)
+	e, ok := s.entries[key]
+	if !ok {
+		now := s.now()
+		e = &Entry{SessionKey: key, SessionID: newSessionID(), CreatedAt: now, UpdatedAt: now, historyLoaded: true}
+		s.entries[key] = e
+	}
+	if !e.historyLoaded {
+		e.historyLoaded = true
+		if s.disk != nil {
+			h, err := s.disk.readHistory(e.SessionID, s.historyLimit())
+			if err != nil {
+				slog.Warn("session: load transcript", "session", e.SessionID, "err", err)
+			}
+			e.History = h
+		}
+	}
+	return e
+}
+
+func (s *Store) saveLocked() {
+	if s.disk == nil {
+		return
+	}
+	if err := s.disk.saveIndex(s.entries); err != nil {
+		slog.Warn("session: save index", "dir", s.disk.dir, "err", err)
+	}
+}
+
+func (s *Store) historyLimit() int {
+	if s.HistoryLimit > 0 {
+		return s.HistoryLimit
+	}
+	return DefaultHistoryLimit
+}
+
+// lastActive falls back to UpdatedAt for entries saved before LastActiveAt existed.
+func (e *Entry) lastActive() time.Time {
+	if e.LastActiveAt.IsZero() {
+		return e.UpdatedAt
+	}
+	return e.LastActiveAt
+}
+
+func (e *Entry) snapshot() Entry {
+	out := *e
+	out.History = append([]llm.Message(nil), e.History...)
+	return out
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func newSessionID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b[:])
+}
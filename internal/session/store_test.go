@@ -0,0 +1,78 @@
+package session
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/llm"
+)
+
+func TestResolveIdleIgnoresOverrideUpdates(t *testing.T) {
+	s := NewStore()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	policy := Policy{IdleTimeout: time.Hour}
+
+	first := s.Append("k", llm.Usage{}, llm.Message{Role: "user", Content: "hi"})
+	now = now.Add(50 * time.Minute)
+	s.Update("k", func(e *Entry) { e.ModelOverride = "m" })
+	now = now.Add(20 * time.Minute)
+
+	e, reason := s.Resolve("k", policy)
+	if reason != ResetIdle {
+		t.Fatalf("reason = %q, want %q", reason, ResetIdle)
+	}
+	if e.SessionID == first.SessionID || e.ModelOverride != "m" {
+		t.Errorf("got session %s override %q, want a new session keeping the override", e.SessionID, e.ModelOverride)
+	}
+}
+
+func TestResolveIdleFresh(t *testing.T) {
+	s := NewStore()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.Append("k", llm.Usage{}, llm.Message{Role: "user", Content: "hi"})
+	now = now.Add(59 * time.Minute)
+	if _, reason := s.Resolve("k", Policy{IdleTimeout: time.Hour}); reason != "" {
+		t.Errorf("reason = %q, want fresh session", reason)
+	}
+}
+
+func TestResetCascadesToSubAgentSessions(t *testing.T) {
+	s := NewStore()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	msg := llm.Message{Role: "user", Content: "hi"}
+	keys := []string{"k", "k:sub:math", "k:sub:math:sub:stats", "k:thread:1:sub:math", "k2:sub:math", "k:subx"}
+	ids := map[string]string{}
+	for _, k := range keys {
+		ids[k] = s.Append(k, llm.Usage{}, msg).SessionID
+	}
+	check := func(step string, reset ...string) {
+		t.Helper()
+		for _, k := range keys {
+			e := s.Get(k)
+			if got, want := e.SessionID != ids[k], slices.Contains(reset, k); got != want {
+				t.Errorf("%s: %s reset = %v, want %v", step, k, got, want)
+			}
+			ids[k] = e.SessionID
+		}
+	}
+
+	s.Reset("k", ResetManual, false)
+	check("reset", "k", "k:sub:math", "k:sub:math:sub:stats")
+
+	// Only the sub-agent sessions used since the last reset roll over again.
+	s.Append("k", llm.Usage{}, msg)
+	s.Append("k:sub:math", llm.Usage{}, msg)
+	now = now.Add(2 * time.Hour)
+	if _, reason := s.Resolve("k", Policy{IdleTimeout: time.Hour}); reason != ResetIdle {
+		t.Fatalf("reason = %q, want %q", reason, ResetIdle)
+	}
+	check("idle", "k", "k:sub:math")
+	if e := s.Get("k:sub:math"); len(e.History) != 0 || e.Usage.Turns != 0 {
+		t.Errorf("sub-agent session kept %d messages", len(e.History))
+	}
+}
@@ -0,0 +1,111 @@
+// Package tmpl renders prompt templates (agent system prompts, message envelopes). Templates are
+// parsed and test-rendered while the config is decoded, so syntax errors, unknown fields and bad
+// calls are reported at load time.
+package tmpl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Vars are the values available to a template, e.g. {{.SenderName}} or {{.Vars.team}}.
+type Vars struct {
+	AgentID      string
+	SessionKey   string
+	Channel      string // provider, e.g. "discord"
+	AccountID    string
+	GuildID      string
+	ChatType     string // "direct", "group", "channel" or "thread"
+	Conversation string // conversation label, e.g. "#general"
+	SenderName   string
+	SenderID     string
+	// Now is the message time in the configured timezone; Date, Time and Weekday are derived from it.
+	Now     time.Time
+	Date    string // 2006-01-02
+	Time    string // 15:04
+	Weekday string
+	// Vars holds custom variables from config (agents.defaults.vars merged with the agent's vars).
+	Vars map[string]string
+}
+
+// SetNow sets Now and the fields derived from it.
+func (v *Vars) SetNow(t time.Time) {
+	v.Now = t
+	v.Date = t.Format("2006-01-02")
+	v.Time = t.Format("15:04")
+	v.Weekday = t.Weekday().String()
+}
+
+var funcs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	// default returns def when s is empty: {{default "someone" .SenderName}}.
+	"default": func(def, s string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	},
+}
+
+// Template is a parsed prompt template. It decodes from a YAML string and encodes back to its source.
+type Template struct {
+	src string
+	t   *template.Template
+}
+
+// Parse parses src and renders it once with empty Vars, so that a misspelled field ({{.Sendr}})
+// fails here rather than on every message; name is used in error messages.
+func Parse(name, src string) (*Template, error) {
+	t, err := template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(io.Discard, Vars{Vars: map[string]string{}}); err != nil {
+		return nil, err
+	}
+	return &Template{src: src, t: t}, nil
+}
+
+// Source returns the template text.
+func (t *Template) Source() string {
+	if t == nil {
+		return ""
+	}
+	return t.src
+}
+
+// Render executes the template with v.
+func (t *Template) Render(v Vars) (string, error) {
+	var b strings.Builder
+	if err := t.t.Execute(&b, v); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// UnmarshalYAML parses the template, so a bad template fails config loading with its line number
+// (and the template's own line:column in the message).
+func (t *Template) UnmarshalYAML(node *yaml.Node) error {
+	var src string
+	if err := node.Decode(&src); err != nil {
+		return err
+	}
+	parsed, err := Parse("prompt", src)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*t = *parsed
+	return nil
+}
+
+// MarshalYAML encodes the template as its source text.
+func (t *Template) MarshalYAML() (any, error) {
+	return t.Source(), nil
+}
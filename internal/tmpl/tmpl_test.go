@@ -0,0 +1,51 @@
+package tmpl
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"fields", "prompt: \"You are {{.AgentID}} talking to {{default \\\"someone\\\" .SenderName}} on {{.Date}}.\"\n", ""},
+		{"custom vars", "prompt: \"Team {{.Vars.team}}, {{upper .Weekday}}\"\n", ""},
+		{"unknown field", "x: 1\nprompt: \"Hi {{.Sendr}}\"\n", `line 2: template: prompt:1:5: executing "prompt" at <.Sendr>: can't evaluate field Sendr`},
+		{"bad call", "prompt: \"{{upper .SenderName .AgentID}}\"\n", `line 1: template: prompt:1:2: executing "prompt" at <upper>: wrong number of args`},
+		{"bad method", "prompt: \"{{.Now.Fmt}}\"\n", `line 1: template: prompt:1:6: executing "prompt" at <.Now.Fmt>: can't evaluate field Fmt`},
+		{"syntax", "prompt: \"{{.AgentID\"\n", "line 1: template: prompt:1: unclosed action"},
+		{"unknown function", "prompt: \"{{shout .AgentID}}\"\n", `line 1: template: prompt:1: function "shout" not defined`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v struct {
+				Prompt *Template `yaml:"prompt"`
+			}
+			err := yaml.Unmarshal([]byte(tt.doc), &v)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tp, err := Parse("prompt", "[{{.SenderName}} in {{default \"DM\" .Conversation}} at {{.Time}}] {{.Vars.team}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := Vars{SenderName: "alice", Vars: map[string]string{"team": "core"}}
+	v.SetNow(time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC))
+	if got, _ := tp.Render(v); got != "[alice in DM at 15:04] core" {
+		t.Errorf("got %q", got)
+	}
+}
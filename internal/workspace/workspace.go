@@ -0,0 +1,136 @@
+// Package workspace loads per-agent prompt files (persona, instructions, notes) from disk.
+// Files are re-read when they change, so they can be edited without a restart.
+package workspace
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaw/openclaw-go/internal/config"
+)
+
+// DefaultMaxChars caps each workspace file included in the system prompt.
+const DefaultMaxChars = 8000
+
+// Files are the workspace files, in the order they are added to the system prompt.
+var Files = []struct{ Name, Heading string }{
+	{"PERSONA.md", "Persona"},
+	{"INSTRUCTIONS.md", "Instructions"},
+	{"NOTES.md", "Notes"},
+}
+
+// DefaultRoot returns ~/.openclaw/agents; each agent's workspace is <root>/<agent id>.
+func DefaultRoot() string {
+	home, _ := os.UserHomeDir()
+	if home == "" {
+		return filepath.Join(".openclaw", "agents")
+	}
+	return filepath.Join(home, ".openclaw", "agents")
+}
+
+// Store reads workspace files and caches them by modification time. It is safe for concurrent use.
+type Store struct {
+	Root     string
+	MaxChars int
+
+	mu    sync.Mutex
+	files map[string]cachedFile
+}
+
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	text    string
+}
+
+// NewStore returns a store for workspaces under root (DefaultRoot if empty).
+func NewStore(root string, maxChars int) *Store {
+	if root == "" {
+		root = DefaultRoot()
+	}
+	root = expandHome(root)
+	if maxChars <= 0 {
+		maxChars = DefaultMaxChars
+	}
+	return &Store{Root: root, MaxChars: maxChars, files: make(map[string]cachedFile)}
+}
+
+// Dir returns the workspace directory of agentID: agents.list[].workspace when set, else <Root>/<id>.
+func (s *Store) Dir(cfg *config.Config, agentID string) string {
+	if a, ok := cfg.Agent(agentID); ok && a.Workspace != "" {
+		return expandHome(a.Workspace)
+	}
+	id := filepath.Base(filepath.Clean("/" + strings.ToLower(strings.TrimSpace(agentID))))
+	if id == "/" || id == "." {
+		id = "main"
+	}
+	return filepath.Join(s.Root, id)
+}
+
+// Prompt assembles the workspace files of agentID into a system prompt section, one "## Heading"
+// block per file that exists and is not empty. It returns "" when there are none.
+func (s *Store) Prompt(cfg *config.Config, agentID string) string {
+	if s == nil {
+		return ""
+	}
+	dir := s.Dir(cfg, agentID)
+	var blocks []string
+	for _, f := range Files {
+		if text := s.read(filepath.Join(dir, f.Name)); text != "" {
+			blocks = append(blocks, "## "+f.Heading+"\n\n"+text)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// read returns the trimmed, size-capped contents of path, re-reading it only when its size or
+// modification time changed. Missing files read as "".
+func (s *Store) read(path string) string {
+	st, err := os.Stat(path)
+	if err != nil || st.IsDir() {
+		if err != nil && !os.IsNotExist(err) {
+			slog.Warn("workspace: stat", "path", path, "err", err)
+		}
+		s.mu.Lock()
+		delete(s.files, path)
+		s.mu.Unlock()
+		return ""
+	}
+	s.mu.Lock()
+	c, ok := s.files[path]
+	s.mu.Unlock()
+	if ok && c.modTime.Equal(st.ModTime()) && c.size == st.Size() {
+		return c.text
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("workspace: read", "path", path, "err", err)
+		return c.text
+	}
+	text := strings.TrimSpace(string(data))
+	if r := []rune(text); len(r) > s.MaxChars {
+		slog.Warn("workspace: file exceeds size cap, truncated", "path", path, "chars", len(r), "max", s.MaxChars)
+		text = string(r[:s.MaxChars])
+	}
+	if ok {
+		slog.Info("workspace: reloaded", "path", path)
+	}
+	s.mu.Lock()
+	s.files[path] = cachedFile{modTime: st.ModTime(), size: st.Size(), text: text}
+	s.mu.Unlock()
+	return text
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, _ := os.UserHomeDir(); home != "" {
+			return filepath.Join(home, p[1:])
+		}
+	}
+	return p
+}